@@ -0,0 +1,238 @@
+// Package analysis computes link-graph metrics, such as PageRank and HITS, and clusters of
+// duplicate pages from the pages visited by the crawler or from a saved report.
+package analysis
+
+import (
+	"encoding/json"
+	"os"
+	"slices"
+
+	"github.com/yusufaine/gocrawler"
+)
+
+// Graph is the directed link graph of a crawl. Nodes are the visited pages and the links found
+// on them, links that were redirected point to the page that they were redirected to, and a page
+// linking to itself is ignored.
+type Graph struct {
+	nodes []string
+	index map[string]int
+	out   [][]int
+	in    [][]int
+	pages map[int]gocrawler.PageInfo
+}
+
+// New builds the graph from the visited pages and the redirects that were followed, keyed by
+// the requested URL (see gocrawler.Client.VisitedRedirects).
+func New(pages map[string]gocrawler.PageInfo, redirects map[string][]gocrawler.RedirectHop) *Graph {
+	resolve := func(link string) string {
+		if hops := redirects[link]; len(hops) > 0 {
+			return hops[len(hops)-1].Location
+		}
+		return link
+	}
+
+	// sort the nodes so that the results do not depend on map iteration order
+	nodeSet := make(map[string]struct{}, len(pages))
+	for link, pi := range pages {
+		nodeSet[link] = struct{}{}
+		for _, out := range pi.Links {
+			nodeSet[resolve(out)] = struct{}{}
+		}
+	}
+	g := &Graph{
+		nodes: make([]string, 0, len(nodeSet)),
+		index: make(map[string]int, len(nodeSet)),
+		pages: make(map[int]gocrawler.PageInfo, len(pages)),
+	}
+	for link := range nodeSet {
+		g.nodes = append(g.nodes, link)
+	}
+	slices.Sort(g.nodes)
+	for i, link := range g.nodes {
+		g.index[link] = i
+	}
+	g.out = make([][]int, len(g.nodes))
+	g.in = make([][]int, len(g.nodes))
+
+	for link, pi := range pages {
+		src := g.index[link]
+		g.pages[src] = pi
+		for _, out := range pi.Links {
+			dst := g.index[resolve(out)]
+			if dst == src || slices.Contains(g.out[src], dst) {
+				continue
+			}
+			g.out[src] = append(g.out[src], dst)
+			g.in[dst] = append(g.in[dst], src)
+		}
+	}
+	for i := range g.nodes {
+		slices.Sort(g.out[i])
+		slices.Sort(g.in[i])
+	}
+	return g
+}
+
+// FromClient builds the graph from the pages visited by the crawler.
+func FromClient(cr *gocrawler.Client) *Graph {
+	return New(cr.VisitedPageInfo, cr.VisitedRedirects)
+}
+
+// LoadReport builds the graph from a report exported by the examples (e.g. explorer), which
+// contains the "page_info" and "redirect_chains" of the crawl.
+func LoadReport(path string) (*Graph, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var report struct {
+		Pages     map[string]gocrawler.PageInfo      `json:"page_info"`
+		Redirects map[string][]gocrawler.RedirectHop `json:"redirect_chains"`
+	}
+	if err := json.Unmarshal(b, &report); err != nil {
+		return nil, err
+	}
+	return New(report.Pages, report.Redirects), nil
+}
+
+// Nodes returns the URLs of all nodes, sorted.
+func (g *Graph) Nodes() []string {
+	return slices.Clone(g.nodes)
+}
+
+// NodeStats are the metrics of a single node. ClickDepth is -1 if the node cannot be reached
+// from the seeds, and Component is the index of the node's strongly-connected component in
+// Result.Components, or -1 if the node is not part of a cycle.
+type NodeStats struct {
+	InDegree   int     `json:"in_degree"`
+	OutDegree  int     `json:"out_degree"`
+	PageRank   float64 `json:"pagerank"`
+	Hub        float64 `json:"hub"`
+	Authority  float64 `json:"authority"`
+	ClickDepth int     `json:"click_depth"`
+	Component  int     `json:"component"`
+}
+
+// Result of Analyse. Components are the strongly-connected components with more than one node,
+// largest first.
+type Result struct {
+	Nodes      map[string]NodeStats `json:"nodes"`
+	Components [][]string           `json:"components"`
+	Orphans    []string             `json:"orphans"`
+}
+
+// Analyse computes all metrics of the graph with the default parameters.
+func (g *Graph) Analyse() Result {
+	pr := g.PageRank(DefaultDamping)
+	hubs, auths := g.HITS()
+	depths := g.ClickDepths()
+	comps := g.Components()
+
+	res := Result{
+		Nodes:      make(map[string]NodeStats, len(g.nodes)),
+		Components: [][]string{},
+		Orphans:    g.Orphans(),
+	}
+	compOf := make(map[string]int)
+	for _, comp := range comps {
+		if len(comp) < 2 {
+			break
+		}
+		for _, link := range comp {
+			compOf[link] = len(res.Components)
+		}
+		res.Components = append(res.Components, comp)
+	}
+
+	for i, link := range g.nodes {
+		comp, ok := compOf[link]
+		if !ok {
+			comp = -1
+		}
+		res.Nodes[link] = NodeStats{
+			InDegree:   len(g.in[i]),
+			OutDegree:  len(g.out[i]),
+			PageRank:   pr[link],
+			Hub:        hubs[link],
+			Authority:  auths[link],
+			ClickDepth: depths[link],
+			Component:  comp,
+		}
+	}
+	return res
+}
+
+// InDegrees returns the number of distinct nodes linking to each node.
+func (g *Graph) InDegrees() map[string]int {
+	degrees := make(map[string]int, len(g.nodes))
+	for i, link := range g.nodes {
+		degrees[link] = len(g.in[i])
+	}
+	return degrees
+}
+
+// OutDegrees returns the number of distinct nodes each node links to.
+func (g *Graph) OutDegrees() map[string]int {
+	degrees := make(map[string]int, len(g.nodes))
+	for i, link := range g.nodes {
+		degrees[link] = len(g.out[i])
+	}
+	return degrees
+}
+
+// Seeds returns the pages that the crawl started from, which are the pages at depth 0.
+func (g *Graph) Seeds() []string {
+	var seeds []string
+	for i, link := range g.nodes {
+		if pi, ok := g.pages[i]; ok && pi.Depth == 0 {
+			seeds = append(seeds, link)
+		}
+	}
+	return seeds
+}
+
+// Orphans returns the visited pages, other than the seeds, that no other page links to. These
+// are usually pages that were only reached through a redirect.
+func (g *Graph) Orphans() []string {
+	orphans := []string{}
+	for i, link := range g.nodes {
+		if pi, ok := g.pages[i]; ok && pi.Depth > 0 && len(g.in[i]) == 0 {
+			orphans = append(orphans, link)
+		}
+	}
+	return orphans
+}
+
+// ClickDepths returns the least number of links that have to be followed to reach each node
+// from any of the seeds, or -1 if the node cannot be reached. This can be lower than the depth
+// recorded by the crawler, which is the depth at which the page was first visited.
+func (g *Graph) ClickDepths() map[string]int {
+	dist := make([]int, len(g.nodes))
+	for i := range dist {
+		dist[i] = -1
+	}
+
+	var queue []int
+	for _, seed := range g.Seeds() {
+		i := g.index[seed]
+		dist[i] = 0
+		queue = append(queue, i)
+	}
+	for len(queue) > 0 {
+		curr := queue[0]
+		queue = queue[1:]
+		for _, next := range g.out[curr] {
+			if dist[next] < 0 {
+				dist[next] = dist[curr] + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	depths := make(map[string]int, len(g.nodes))
+	for i, link := range g.nodes {
+		depths[link] = dist[i]
+	}
+	return depths
+}
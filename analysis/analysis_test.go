@@ -0,0 +1,95 @@
+package analysis_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/yusufaine/gocrawler"
+	"github.com/yusufaine/gocrawler/analysis"
+)
+
+// a <-> b, a -> old (redirected to c), b -> c -> d, and e which is only reachable by a redirect
+func newGraph() *analysis.Graph {
+	pages := map[string]gocrawler.PageInfo{
+		"a": {Depth: 0, Links: []string{"b", "old"}},
+		"b": {Depth: 1, Parent: "a", Links: []string{"a", "b", "c"}},
+		"c": {Depth: 1, Parent: "a", Links: []string{"d"}},
+		"e": {Depth: 1, Parent: "c"},
+	}
+	redirects := map[string][]gocrawler.RedirectHop{
+		"old": {{URL: "old", StatusCode: 301, Location: "c"}},
+	}
+	return analysis.New(pages, redirects)
+}
+
+func TestAnalyse(t *testing.T) {
+	res := newGraph().Analyse()
+
+	expDegrees := map[string][2]int{"a": {1, 2}, "b": {1, 2}, "c": {2, 1}, "d": {1, 0}, "e": {0, 0}}
+	expDepths := map[string]int{"a": 0, "b": 1, "c": 1, "d": 2, "e": -1}
+	for link, exp := range expDegrees {
+		ns, ok := res.Nodes[link]
+		if !ok {
+			t.Fatalf("Expected node %s", link)
+		}
+		if ns.InDegree != exp[0] || ns.OutDegree != exp[1] {
+			t.Errorf("Expected %s to have in/out degree %v, got %d/%d", link, exp, ns.InDegree, ns.OutDegree)
+		}
+		if ns.ClickDepth != expDepths[link] {
+			t.Errorf("Expected %s to have click depth %d, got %d", link, expDepths[link], ns.ClickDepth)
+		}
+	}
+	if len(res.Nodes) != len(expDegrees) {
+		t.Errorf("Expected %d nodes, got %d", len(expDegrees), len(res.Nodes))
+	}
+
+	if exp := [][]string{{"a", "b"}}; !reflect.DeepEqual(res.Components, exp) {
+		t.Errorf("Expected components %v, got %v", exp, res.Components)
+	}
+	if res.Nodes["a"].Component != 0 || res.Nodes["c"].Component != -1 {
+		t.Errorf("Expected a in component 0 and c in none, got %d and %d", res.Nodes["a"].Component, res.Nodes["c"].Component)
+	}
+	if exp := []string{"e"}; !reflect.DeepEqual(res.Orphans, exp) {
+		t.Errorf("Expected orphans %v, got %v", exp, res.Orphans)
+	}
+
+	var sum float64
+	for _, ns := range res.Nodes {
+		sum += ns.PageRank
+	}
+	if math.Abs(sum-1) > 1e-6 {
+		t.Errorf("Expected PageRank to sum to 1, got %f", sum)
+	}
+	if res.Nodes["d"].PageRank <= res.Nodes["b"].PageRank {
+		t.Errorf("Expected d to rank above b, got %f and %f", res.Nodes["d"].PageRank, res.Nodes["b"].PageRank)
+	}
+
+	// c is linked to by both hubs
+	for link, ns := range res.Nodes {
+		if link != "c" && ns.Authority >= res.Nodes["c"].Authority {
+			t.Errorf("Expected c to be the top authority, %s has %f vs %f", link, ns.Authority, res.Nodes["c"].Authority)
+		}
+	}
+}
+
+func TestDuplicateClusters(t *testing.T) {
+	pages := map[string]gocrawler.PageInfo{
+		"a":     {ContentHash: "1", SimHash: 0b0001},
+		"a?s=1": {ContentHash: "2", SimHash: 0b0111},
+		"a?s=2": {ContentHash: "3", SimHash: 0b1111}, // only near a?s=1
+		"b":     {ContentHash: "4", SimHash: 0xff00},
+		"b2":    {ContentHash: "4"},
+		"c":     {ContentHash: "5", SimHash: 0xf0f0},
+	}
+
+	exp := [][]string{{"a", "a?s=1", "a?s=2"}, {"b", "b2"}}
+	if got := analysis.DuplicateClusters(pages, 2); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected clusters %v, got %v", exp, got)
+	}
+
+	exp = [][]string{{"b", "b2"}}
+	if got := analysis.DuplicateClusters(pages, -1); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected only exact duplicates %v, got %v", exp, got)
+	}
+}
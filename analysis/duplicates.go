@@ -0,0 +1,95 @@
+package analysis
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/yusufaine/gocrawler"
+	"github.com/yusufaine/gocrawler/fingerprint"
+)
+
+// DuplicateClusters groups the pages that have the same content hash, or whose SimHash differ
+// by at most maxDistance bits (see fingerprint.DefaultDistance), a negative maxDistance only
+// groups exact duplicates. Pages are grouped transitively, so a cluster may contain pages that
+// are further apart than maxDistance if there are pages in between.
+//
+// Only clusters with more than one page are returned, largest first, and the pages of each
+// cluster are sorted.
+func DuplicateClusters(pages map[string]gocrawler.PageInfo, maxDistance int) [][]string {
+	links := make([]string, 0, len(pages))
+	for link := range pages {
+		links = append(links, link)
+	}
+	slices.Sort(links)
+
+	// union-find over the index of each page in links
+	parent := make([]int, len(links))
+	for i := range parent {
+		parent[i] = i
+	}
+	find := func(i int) int {
+		root := i
+		for parent[root] != root {
+			root = parent[root]
+		}
+		for parent[i] != root {
+			parent[i], i = root, parent[i]
+		}
+		return root
+	}
+	union := func(i, j int) {
+		parent[find(j)] = find(i)
+	}
+
+	// exact duplicates are joined to the first page with the content hash, and near-duplicates
+	// to every page within maxDistance in the index, so that pages are not compared pairwise
+	index := make(map[string]int, len(links))
+	byHash := make(map[string]int)
+	var ix *fingerprint.Index
+	if maxDistance >= 0 {
+		ix = fingerprint.NewIndex(maxDistance)
+	}
+	for i, link := range links {
+		index[link] = i
+		p := pages[link]
+		if p.ContentHash != "" {
+			if first, ok := byHash[p.ContentHash]; ok {
+				union(first, i)
+			} else {
+				byHash[p.ContentHash] = i
+			}
+		}
+		if ix != nil && p.SimHash != 0 {
+			ix.Add(link, p.SimHash)
+		}
+	}
+	if ix != nil {
+		for i, link := range links {
+			if p := pages[link]; p.SimHash != 0 {
+				for _, near := range ix.Within(p.SimHash) {
+					union(i, index[near])
+				}
+			}
+		}
+	}
+
+	groups := make(map[int][]string)
+	for i, link := range links {
+		root := find(i)
+		groups[root] = append(groups[root], link)
+	}
+
+	clusters := [][]string{}
+	for _, group := range groups {
+		if len(group) > 1 {
+			clusters = append(clusters, group)
+		}
+	}
+	slices.SortFunc(clusters, func(a, b []string) int {
+		if len(a) != len(b) {
+			return len(b) - len(a)
+		}
+		return strings.Compare(a[0], b[0])
+	})
+	return clusters
+}
@@ -0,0 +1,205 @@
+package analysis
+
+import (
+	"math"
+	"slices"
+	"strings"
+)
+
+const (
+	DefaultDamping = 0.85
+
+	maxIterations = 100
+	tolerance     = 1e-9
+)
+
+// PageRank returns the PageRank of each node, which sum to 1. The rank of nodes without outgoing
+// links (e.g. links that were not crawled) is spread evenly across all nodes.
+func (g *Graph) PageRank(damping float64) map[string]float64 {
+	n := len(g.nodes)
+	ranks := make(map[string]float64, n)
+	if n == 0 {
+		return ranks
+	}
+
+	pr := make([]float64, n)
+	for i := range pr {
+		pr[i] = 1 / float64(n)
+	}
+	next := make([]float64, n)
+	for iter := 0; iter < maxIterations; iter++ {
+		var dangling float64
+		for i := range g.nodes {
+			if len(g.out[i]) == 0 {
+				dangling += pr[i]
+			}
+		}
+
+		base := (1-damping)/float64(n) + damping*dangling/float64(n)
+		for i := range next {
+			next[i] = base
+		}
+		for i := range g.nodes {
+			if len(g.out[i]) == 0 {
+				continue
+			}
+			share := damping * pr[i] / float64(len(g.out[i]))
+			for _, j := range g.out[i] {
+				next[j] += share
+			}
+		}
+
+		var diff float64
+		for i := range pr {
+			diff += math.Abs(next[i] - pr[i])
+		}
+		pr, next = next, pr
+		if diff < tolerance {
+			break
+		}
+	}
+
+	for i, link := range g.nodes {
+		ranks[link] = pr[i]
+	}
+	return ranks
+}
+
+// HITS returns the hub and authority scores of each node, normalised so that the squares of the
+// scores sum to 1. Good hubs link to many good authorities, and good authorities are linked to by
+// many good hubs.
+func (g *Graph) HITS() (map[string]float64, map[string]float64) {
+	n := len(g.nodes)
+	hub := make([]float64, n)
+	auth := make([]float64, n)
+	for i := range hub {
+		hub[i] = 1
+	}
+
+	for iter := 0; iter < maxIterations; iter++ {
+		prevHub := slices.Clone(hub)
+		for i := range g.nodes {
+			auth[i] = 0
+			for _, j := range g.in[i] {
+				auth[i] += hub[j]
+			}
+		}
+		normalise(auth)
+		for i := range g.nodes {
+			hub[i] = 0
+			for _, j := range g.out[i] {
+				hub[i] += auth[j]
+			}
+		}
+		normalise(hub)
+
+		var diff float64
+		for i := range hub {
+			diff += math.Abs(hub[i] - prevHub[i])
+		}
+		if diff < tolerance {
+			break
+		}
+	}
+
+	hubs := make(map[string]float64, n)
+	auths := make(map[string]float64, n)
+	for i, link := range g.nodes {
+		hubs[link] = hub[i]
+		auths[link] = auth[i]
+	}
+	return hubs, auths
+}
+
+func normalise(v []float64) {
+	var sum float64
+	for _, x := range v {
+		sum += x * x
+	}
+	if sum == 0 {
+		return
+	}
+	norm := math.Sqrt(sum)
+	for i := range v {
+		v[i] /= norm
+	}
+}
+
+// Components returns the strongly-connected components of the graph, where every node can be
+// reached from every other node in the same component. Components are sorted by size, largest
+// first, and the nodes of each component are sorted.
+func (g *Graph) Components() [][]string {
+	// iterative version of Tarjan's algorithm so that large crawls do not overflow the stack
+	var (
+		n       = len(g.nodes)
+		index   = make([]int, n)
+		low     = make([]int, n)
+		onStack = make([]bool, n)
+		stack   []int
+		comps   [][]string
+		counter = 1
+	)
+	type frame struct{ node, edge int }
+
+	for root := range g.nodes {
+		if index[root] != 0 {
+			continue
+		}
+
+		callStack := []frame{{node: root}}
+		index[root], low[root] = counter, counter
+		counter++
+		stack = append(stack, root)
+		onStack[root] = true
+
+		for len(callStack) > 0 {
+			f := &callStack[len(callStack)-1]
+			if f.edge < len(g.out[f.node]) {
+				next := g.out[f.node][f.edge]
+				f.edge++
+				if index[next] == 0 {
+					index[next], low[next] = counter, counter
+					counter++
+					stack = append(stack, next)
+					onStack[next] = true
+					callStack = append(callStack, frame{node: next})
+				} else if onStack[next] {
+					low[f.node] = min(low[f.node], index[next])
+				}
+				continue
+			}
+
+			// all edges visited, pop the node and pass its low link to the caller
+			node := f.node
+			callStack = callStack[:len(callStack)-1]
+			if len(callStack) > 0 {
+				parent := callStack[len(callStack)-1].node
+				low[parent] = min(low[parent], low[node])
+			}
+			if low[node] != index[node] {
+				continue
+			}
+
+			var comp []string
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				comp = append(comp, g.nodes[top])
+				if top == node {
+					break
+				}
+			}
+			slices.Sort(comp)
+			comps = append(comps, comp)
+		}
+	}
+
+	slices.SortStableFunc(comps, func(a, b []string) int {
+		if len(a) != len(b) {
+			return len(b) - len(a)
+		}
+		return strings.Compare(a[0], b[0])
+	})
+	return comps
+}
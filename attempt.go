@@ -0,0 +1,111 @@
+package gocrawler
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"syscall"
+)
+
+// Outcome describes what happened when the crawler attempted to visit a URL
+type Outcome string
+
+const (
+	OutcomeOK               Outcome = "ok"
+	OutcomeHTTPError        Outcome = "http_error"
+	OutcomeNetworkError     Outcome = "network_error"
+	OutcomeRedirectError    Outcome = "redirect_error"
+	OutcomeFiltered         Outcome = "filtered"
+	OutcomeBlacklisted      Outcome = "blacklisted"
+	OutcomeRobotsDisallowed Outcome = "robots_disallowed"
+	OutcomeDepthExceeded    Outcome = "depth_exceeded"
+	OutcomeInvalidURL       Outcome = "invalid_url"
+	OutcomeTrap             Outcome = "trap"
+	OutcomeUnchanged        Outcome = "unchanged"
+	OutcomeOutOfScope       Outcome = "out_of_scope"
+)
+
+// IsError reports whether the outcome is a failure to fetch the URL or an error status, these
+// are streamed as error events.
+func (o Outcome) IsError() bool {
+	switch o {
+	case OutcomeHTTPError, OutcomeNetworkError, OutcomeRedirectError, OutcomeInvalidURL:
+		return true
+	}
+	return false
+}
+
+// RecordAttempt stores the outcome of an attempt to visit the link. This is used by the crawler
+// itself but is exported so that link extractors can record links that they skip (e.g. links
+// disallowed by robots.txt).
+//
+// If the link has already been attempted, the existing outcome is kept unless it was only
+// skipped for exceeding the max depth, and the depth is always the lowest.
+func (c *Client) RecordAttempt(link string, ai AttemptInfo) {
+	c.AttemptMutex.Lock()
+	if prev, ok := c.Attempts[link]; ok &&
+		(ai.Outcome == OutcomeDepthExceeded || prev.Outcome != OutcomeDepthExceeded) {
+		prev.Depth = min(prev.Depth, ai.Depth)
+		c.Attempts[link] = prev
+		c.AttemptMutex.Unlock()
+		return
+	}
+	c.Attempts[link] = ai
+	c.AttemptMutex.Unlock()
+
+	if ai.Outcome.IsError() {
+		c.emit(Event{Type: EventError, URL: link, Attempt: &ai})
+	}
+}
+
+// Records a failed request, classifying the error as a redirect error or a network error.
+func (c *Client) recordError(link, parent string, depth int, err error) {
+	ai := AttemptInfo{
+		Depth:  depth,
+		Parent: parent,
+		Error:  err.Error(),
+	}
+	switch {
+	case errors.Is(err, ErrBlacklistedRedirect):
+		ai.Outcome = OutcomeBlacklisted
+	case errors.Is(err, ErrCrossHostRedirect),
+		errors.Is(err, ErrRedirectLoop),
+		errors.Is(err, ErrTooManyRedirects):
+		ai.Outcome = OutcomeRedirectError
+	default:
+		ai.Outcome = OutcomeNetworkError
+		ai.ErrorClass = classifyNetError(err)
+	}
+	c.RecordAttempt(link, ai)
+}
+
+// Groups network errors into broad classes so that they can be aggregated in reports.
+func classifyNetError(err error) string {
+	var (
+		dnsErr  *net.DNSError
+		netErr  net.Error
+		certErr *tls.CertificateVerificationError
+		unkErr  x509.UnknownAuthorityError
+		hostErr x509.HostnameError
+		recErr  tls.RecordHeaderError
+	)
+	switch {
+	case errors.As(err, &dnsErr):
+		return "dns"
+	case errors.Is(err, context.DeadlineExceeded),
+		errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return "connection_refused"
+	case errors.Is(err, syscall.ECONNRESET):
+		return "connection_reset"
+	case errors.As(err, &certErr),
+		errors.As(err, &unkErr),
+		errors.As(err, &hostErr),
+		errors.As(err, &recErr):
+		return "tls"
+	}
+	return "other"
+}
@@ -0,0 +1,26 @@
+package gocrawler
+
+import (
+	"io"
+)
+
+// Reads the response body up to the max body size, bodies that are larger are truncated. The
+// body is read in full rather than tokenised as it is received, as the same bytes are hashed,
+// stored, transcoded and passed to the link extractor, so the max body size is what bounds the
+// memory used per response.
+func (c *Client) readBody(r io.Reader) ([]byte, bool, error) {
+	if c.maxBodyBytes <= 0 {
+		body, err := io.ReadAll(r)
+		return body, false, err
+	}
+
+	// read an extra byte to know if the body was truncated
+	body, err := io.ReadAll(io.LimitReader(r, c.maxBodyBytes+1))
+	if err != nil {
+		return nil, false, err
+	}
+	if int64(len(body)) > c.maxBodyBytes {
+		return body[:c.maxBodyBytes], true, nil
+	}
+	return body, false, nil
+}
@@ -0,0 +1,69 @@
+package gocrawler
+
+import (
+	"bytes"
+	"mime"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/log"
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+)
+
+var (
+	utf8BOM          = []byte("\xef\xbb\xbf")
+	xmlEncodingRegex = regexp.MustCompile(`^\s*<\?xml[^>]*encoding\s*=\s*["']([^"']+)["']`)
+)
+
+// DetectCharset returns the encoding of a text response from, in order of precedence, its byte
+// order mark, the charset of the Content-Type header, and the <meta charset> of HTML or the
+// encoding of the XML declaration. Bodies without any of these are UTF-8 if they are valid
+// UTF-8, and windows-1252 otherwise, as browsers do.
+func DetectCharset(body []byte, contentType string) (encoding.Encoding, string) {
+	enc, name, certain := charset.DetermineEncoding(body, contentType)
+	if certain {
+		return enc, name
+	}
+
+	if m := xmlEncodingRegex.FindSubmatch(body[:min(len(body), 1024)]); m != nil {
+		if xmlEnc, xmlName := charset.Lookup(string(m[1])); xmlEnc != nil {
+			return xmlEnc, xmlName
+		}
+	}
+	if name == "windows-1252" && utf8.Valid(body) {
+		return encoding.Nop, "utf-8"
+	}
+	return enc, name
+}
+
+// Reports whether the media type is text that should be transcoded to UTF-8 before links are
+// extracted from it, binary formats such as PDF and JSON, which is always UTF-8, are not.
+func isTranscodable(contentType string) bool {
+	mt := mediaType(contentType)
+	return mt == "" || strings.HasPrefix(mt, "text/") ||
+		mt == "application/xml" || strings.HasSuffix(mt, "+xml")
+}
+
+// Transcodes a text body to UTF-8, returning the body as is if it is not text, or if it cannot
+// be decoded. The name of the detected charset is empty for bodies that are not text.
+func toUTF8(body []byte, contentType string) ([]byte, string) {
+	if _, _, err := mime.ParseMediaType(contentType); err != nil && contentType != "" {
+		contentType = ""
+	}
+	if !isTranscodable(contentType) {
+		return body, ""
+	}
+
+	enc, name := DetectCharset(body, contentType)
+	if enc == encoding.Nop || name == "utf-8" {
+		return bytes.TrimPrefix(body, utf8BOM), name
+	}
+	decoded, err := enc.NewDecoder().Bytes(body)
+	if err != nil {
+		log.Debug("unable to transcode body to utf-8", "charset", name, "error", err)
+		return body, name
+	}
+	return decoded, name
+}
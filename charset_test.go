@@ -0,0 +1,56 @@
+package gocrawler
+
+import "testing"
+
+func TestDetectCharset(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		exp         string
+	}{
+		{"utf-8 bom", "\xef\xbb\xbf<p>caf\xc3\xa9</p>", "text/html; charset=iso-8859-1", "utf-8"},
+		{"utf-16le bom", "\xff\xfe<\x00p\x00>\x00", "text/html", "utf-16le"},
+		{"header", "<p>caf\xe9</p>", "text/html; charset=ISO-8859-1", "windows-1252"},
+		{"header over meta", `<meta charset="shift_jis"><p>café</p>`, "text/html; charset=utf-8", "utf-8"},
+		{"meta charset", `<html><head><meta charset="shift_jis"></head>`, "text/html", "shift_jis"},
+		{"meta http-equiv", `<meta http-equiv="Content-Type" content="text/html; charset=euc-kr">`, "text/html", "euc-kr"},
+		{"xml declaration", `<?xml version="1.0" encoding="ISO-8859-2"?><urlset></urlset>`, "application/xml", "iso-8859-2"},
+		{"xml declaration with single quotes", "  <?xml version='1.0' encoding='koi8-r'?><rss></rss>", "text/xml", "koi8-r"},
+		{"unknown xml encoding", `<?xml version="1.0" encoding="x-unknown"?><rss></rss>`, "text/xml", "utf-8"},
+		{"valid utf-8", "<p>caf\xc3\xa9</p>", "text/html", "utf-8"},
+		{"windows-1252 fallback", "<p>caf\xe9 \x93quoted\x94</p>", "text/html", "windows-1252"},
+		{"no content type", "plain ascii", "", "utf-8"},
+	}
+	for _, tt := range tests {
+		if _, got := DetectCharset([]byte(tt.body), tt.contentType); got != tt.exp {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.exp, got)
+		}
+	}
+}
+
+func TestToUTF8(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		exp         string
+		charset     string
+	}{
+		{"utf-8 bom is removed", "\xef\xbb\xbf<p>caf\xc3\xa9</p>", "text/html", "<p>café</p>", "utf-8"},
+		{"header", "<p>caf\xe9</p>", "text/html; charset=iso-8859-1", "<p>café</p>", "windows-1252"},
+		{"meta", `<meta charset="windows-1251"><p>` + "\xcf\xf0\xe8\xe2\xe5\xf2" + `</p>`, "text/html", `<meta charset="windows-1251"><p>Привет</p>`, "windows-1251"},
+		{"xml declaration", `<?xml version="1.0" encoding="ISO-8859-2"?><loc>` + "\xb3\xf3d\xbc" + `</loc>`, "application/rss+xml", `<?xml version="1.0" encoding="ISO-8859-2"?><loc>łódź</loc>`, "iso-8859-2"},
+		{"windows-1252 fallback", "<p>\x93quoted\x94</p>", "text/plain", "<p>“quoted”</p>", "windows-1252"},
+		{"invalid content type", "<p>caf\xe9</p>", "text/html; charset", "<p>café</p>", "windows-1252"},
+		// bodies that are not text are left as they are
+		{"pdf", "%PDF-1.7 caf\xe9", "application/pdf", "%PDF-1.7 caf\xe9", ""},
+		{"json", "{\"a\": \"caf\xe9\"}", "application/json", "{\"a\": \"caf\xe9\"}", ""},
+	}
+	for _, tt := range tests {
+		got, charset := toUTF8([]byte(tt.body), tt.contentType)
+		if string(got) != tt.exp || charset != tt.charset {
+			t.Errorf("%s: expected %q (%s), got %q (%s)", tt.name, tt.exp, tt.charset, got, charset)
+		}
+	}
+}
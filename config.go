@@ -1,18 +1,43 @@
 package gocrawler
 
 import (
+	"io"
 	"net/url"
 	"time"
+
+	"github.com/yusufaine/gocrawler/hostrule"
+	"github.com/yusufaine/gocrawler/scope"
+	"github.com/yusufaine/gocrawler/scrape"
 )
 
 // This file contains the necessary config for the crawler
 
 type Config struct {
-	BlacklistHosts map[string]struct{} // hosts to blacklist
-	MaxDepth       int                 // max depth from seed
-	MaxRetries     int                 // max retries for HTTP requests
-	MaxRPS         float64             // max requests per second
-	ProxyURL       *url.URL            // proxy URL, if any. useful to avoid IP bans
-	SeedURLs       []string            // where to start crawling from
-	Timeout        time.Duration       // timeout for HTTP requests
+	AllowHosts        hostrule.Rules      // if set, only links to hosts that match are crawled, and more specific rules override BlacklistHosts
+	BlacklistHosts    hostrule.Rules      // hosts whose links are not crawled
+	ContentHandlers   ContentHandlers     // if set, links are extracted with the handler of the response's media type, falling back to the LinkExtractor
+	ContentStore      ContentStore        // where page bodies are stored, defaults to an in-memory store
+	Corpus            io.Writer           // if set, the main text of HTML pages is streamed to it as a JSON Lines text corpus during the crawl, implies ExtractText. pages carried forward from Previous are left out as their body is not received again
+	DetectDuplicates  bool                // record the page that each page is a duplicate or near-duplicate of in PageInfo.DuplicateOf, implied by SkipNearDups
+	Events            io.Writer           // if set, pages, hosts and errors are streamed to it as NDJSON during the crawl
+	ExtractPageMeta   bool                // parse the metadata of HTML pages and the X-Robots-Tag of any page into PageInfo.PageMeta, and honour their robots noindex/nofollow and canonical URL
+	ExtractText       bool                // extract the main text of HTML pages without navigation, footers, ads and scripts, with its word count and reading time in PageInfo.Article
+	HARRecorder       *HARRecorder        // if set, every HTTP request is recorded as a HAR log
+	MaxBodyBytes      int64               // max bytes read from a response body, larger bodies are truncated. no limit if <= 0
+	MaxDepth          int                 // max depth from seed
+	MaxRedirects      int                 // max redirect hops to follow, defaults to 10 if 0 and disabled if < 0
+	MaxRetries        int                 // max retries for HTTP requests
+	MaxRPS            float64             // max requests per second
+	NearDupDistance   int                 // max SimHash distance of near-duplicate pages, defaults to 3 if 0 and only exact duplicates if < 0
+	Previous          map[string]PageInfo // pages of a previous crawl, known pages are requested conditionally and carried forward if unchanged
+	ProxyURL          *url.URL            // proxy URL, if any. useful to avoid IP bans
+	Recorder          ExchangeRecorder    // notified of every HTTP exchange, if any. useful to archive crawls
+	SameHostRedirects bool                // only follow redirects that stay on the host of the requested URL
+	Scope             *scope.Scope        // include and exclude rules for the links to crawl, applied to the links of any extractor
+	ScrapeRules       *scrape.Rules       // if set, structured data is scraped from HTML pages into PageInfo.Scraped with the rule sets that match their URL
+	SeedURLs          []string            // where to start crawling from
+	SkipNearDups      bool                // do not follow the links of pages that are near-duplicates of a page already visited
+	SkipUnchanged     bool                // carry forward the known pages linked from unchanged pages without requesting them
+	Timeout           time.Duration       // timeout for HTTP requests
+	Traps             TrapConfig          // heuristics to avoid crawler traps, all disabled by default
 }
@@ -0,0 +1,400 @@
+package gocrawler
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"golang.org/x/net/html"
+)
+
+// ContentHandler extracts the links and metadata of a response body of the media types that it
+// is registered for in ContentHandlers.
+type ContentHandler func(c *Client, currLink string, body []byte) ContentResult
+
+// ContentResult is what a ContentHandler extracted from a response body. The metadata is stored
+// in PageInfo.Metadata, e.g. the title of a feed or a PDF.
+type ContentResult struct {
+	Links []string
+	Meta  map[string]string
+}
+
+// ContentHandlers is a registry of handlers keyed by media type, e.g. "application/pdf". If it is
+// set in the Config, the crawler extracts links from every response with the handler of its
+// Content-Type, so that crawls can traverse sites of mixed content.
+type ContentHandlers map[string]ContentHandler
+
+// DefaultContentHandlers returns the built-in handlers for HTML, XHTML, XML sitemaps, RSS and
+// Atom feeds, JSON, plain text and PDF.
+func DefaultContentHandlers() ContentHandlers {
+	return ContentHandlers{
+		"text/html":             HTMLHandler,
+		"application/xhtml+xml": HTMLHandler,
+		"application/xml":       XMLHandler,
+		"text/xml":              XMLHandler,
+		"application/rss+xml":   XMLHandler,
+		"application/atom+xml":  XMLHandler,
+		"application/json":      JSONHandler,
+		"text/plain":            TextHandler,
+		"application/pdf":       PDFHandler,
+	}
+}
+
+// Lookup returns the handler for the media type of the Content-Type header. Media types with a
+// structured syntax suffix fall back to the handler of the suffix, e.g. "application/ld+json"
+// uses the handler of "application/json" if it has none of its own.
+func (h ContentHandlers) Lookup(contentType string) (ContentHandler, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+	if handler, ok := h[mediaType]; ok {
+		return handler, true
+	}
+	if i := strings.LastIndex(mediaType, "+"); i >= 0 {
+		handler, ok := h["application/"+mediaType[i+1:]]
+		return handler, ok
+	}
+	return nil, false
+}
+
+// Returns the media type of the Content-Type header without parameters, or the header as is if
+// it cannot be parsed.
+func mediaType(contentType string) string {
+	if mt, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mt
+	}
+	return contentType
+}
+
+// MediaTypes returns the sorted media types that have a handler.
+func (h ContentHandlers) MediaTypes() []string {
+	types := make([]string, 0, len(h))
+	for t := range h {
+		types = append(types, t)
+	}
+	slices.Sort(types)
+	return types
+}
+
+// Matcher returns a matcher of responses with a handler, to be used instead of IsHtmlContent,
+// e.g. And(ResponseMatcher(IsOkResponse), handlers.Matcher()).
+func (h ContentHandlers) Matcher() NamedMatcher {
+	return Named("HasContentHandler", func(resp *http.Response) bool {
+		_, ok := h.Lookup(resp.Header.Get("Content-Type"))
+		return ok
+	})
+}
+
+// HTMLHandler extracts links with the LinkExtractor of the crawler, and the <title> of the page.
+func HTMLHandler(c *Client, currLink string, body []byte) ContentResult {
+	res := ContentResult{Links: c.le(c, currLink, body)}
+	if title := htmlTitle(body); title != "" {
+		res.Meta = map[string]string{"title": title}
+	}
+	return res
+}
+
+func htmlTitle(body []byte) string {
+	z := html.NewTokenizer(bytes.NewReader(body))
+	inTitle := false
+	for tt := z.Next(); tt != html.ErrorToken; tt = z.Next() {
+		switch tt {
+		case html.StartTagToken:
+			name, _ := z.TagName()
+			inTitle = string(name) == "title"
+		case html.TextToken:
+			if inTitle {
+				return strings.Join(strings.Fields(string(z.Text())), " ")
+			}
+		case html.EndTagToken:
+			inTitle = false
+		}
+	}
+	return ""
+}
+
+// XMLHandler extracts the <loc> of XML sitemaps and sitemap indexes, the <link> of RSS channels
+// and items, and the <link href="..."> of Atom feeds and entries. The format and the title of
+// feeds are returned as metadata. Other XML documents have no links.
+func XMLHandler(c *Client, currLink string, body []byte) ContentResult {
+	d := xml.NewDecoder(bytes.NewReader(body))
+	d.Strict = false
+	d.CharsetReader = func(_ string, r io.Reader) (io.Reader, error) { return r, nil }
+
+	var (
+		raw   []string
+		path  []string
+		root  string
+		title string
+	)
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			if err != io.EOF {
+				log.Debug("unable to parse xml", "url", currLink, "error", err)
+			}
+			break
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if root == "" {
+				root = t.Name.Local
+			}
+			path = append(path, t.Name.Local)
+			if root == "feed" && t.Name.Local == "link" {
+				rel, href := "alternate", ""
+				for _, attr := range t.Attr {
+					switch attr.Name.Local {
+					case "rel":
+						rel = attr.Value
+					case "href":
+						href = attr.Value
+					}
+				}
+				if rel == "alternate" || rel == "related" {
+					raw = append(raw, href)
+				}
+			}
+		case xml.EndElement:
+			if len(path) > 0 {
+				path = path[:len(path)-1]
+			}
+		case xml.CharData:
+			if len(path) == 0 {
+				continue
+			}
+			text := strings.TrimSpace(string(t))
+			switch elem := path[len(path)-1]; {
+			case elem == "loc" && (root == "urlset" || root == "sitemapindex"):
+				raw = append(raw, text)
+			case elem == "link" && (root == "rss" || root == "RDF"):
+				raw = append(raw, text)
+			case elem == "title" && title == "" && len(path) <= 3:
+				title = text
+			}
+		}
+	}
+
+	meta := map[string]string{}
+	switch root {
+	case "urlset":
+		meta["format"] = "sitemap"
+	case "sitemapindex":
+		meta["format"] = "sitemap_index"
+	case "rss", "RDF":
+		meta["format"] = "rss"
+	case "feed":
+		meta["format"] = "atom"
+	default:
+		meta["format"] = "xml"
+	}
+	if title != "" {
+		meta["title"] = title
+	}
+	return ContentResult{Links: resolveLinks(currLink, raw), Meta: meta}
+}
+
+// JSONHandler extracts the strings of a JSON document that look like URLs, absolute http(s)
+// URLs and paths relative to the host, e.g. "/api/items?page=2".
+func JSONHandler(c *Client, currLink string, body []byte) ContentResult {
+	d := json.NewDecoder(bytes.NewReader(body))
+	d.UseNumber()
+	var v any
+	if err := d.Decode(&v); err != nil {
+		log.Debug("unable to parse json", "url", currLink, "error", err)
+		return ContentResult{}
+	}
+
+	var raw []string
+	var walk func(v any)
+	walk = func(v any) {
+		switch t := v.(type) {
+		case map[string]any:
+			for _, child := range t {
+				walk(child)
+			}
+		case []any:
+			for _, child := range t {
+				walk(child)
+			}
+		case string:
+			if looksLikeURL(t) {
+				raw = append(raw, t)
+			}
+		}
+	}
+	walk(v)
+	return ContentResult{Links: resolveLinks(currLink, raw)}
+}
+
+func looksLikeURL(s string) bool {
+	if s == "" || strings.ContainsAny(s, " \t\r\n") {
+		return false
+	}
+	lower := strings.ToLower(s)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") ||
+		(strings.HasPrefix(s, "/") && len(s) > 1)
+}
+
+var textURLRegex = regexp.MustCompile(`(?i)https?://[^\s<>"'` + "`" + `]+`)
+
+// TextHandler extracts the absolute http(s) URLs in plain text, without trailing punctuation.
+func TextHandler(c *Client, currLink string, body []byte) ContentResult {
+	var raw []string
+	for _, m := range textURLRegex.FindAll(body, -1) {
+		raw = append(raw, trimURLPunctuation(string(m)))
+	}
+	return ContentResult{Links: resolveLinks(currLink, raw)}
+}
+
+// Trims the punctuation that ends the sentence around a URL, keeping a closing parenthesis that
+// is part of the URL, e.g. https://en.wikipedia.org/wiki/Go_(programming_language).
+func trimURLPunctuation(s string) string {
+	for s != "" {
+		switch last := s[len(s)-1]; {
+		case strings.IndexByte(".,;:!?]}'\"", last) >= 0:
+		case last == ')' && strings.Count(s, "(") < strings.Count(s, ")"):
+		default:
+			return s
+		}
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+var (
+	pdfURIRegex    = regexp.MustCompile(`/URI\s*\(((?:\\.|[^\\)])*)\)`)
+	pdfTitleRegex  = regexp.MustCompile(`/Title\s*\(((?:\\.|[^\\)])*)\)`)
+	pdfStreamRegex = regexp.MustCompile(`>>\s*stream\r?\n`)
+	pdfFlateRegex  = regexp.MustCompile(`/Filter\s*(?:/FlateDecode|\[\s*/FlateDecode\s*\])`)
+)
+
+// maxPDFInflatedBytes limits how much the compressed streams of a PDF are inflated to in total.
+const maxPDFInflatedBytes = 10 << 20
+
+// PDFHandler extracts the URIs of link annotations, and the title of the document, including
+// those in compressed object streams. Links in the text of the pages are not extracted.
+func PDFHandler(c *Client, currLink string, body []byte) ContentResult {
+	sections := [][]byte{body}
+	budget := int64(maxPDFInflatedBytes)
+	for _, loc := range pdfStreamRegex.FindAllIndex(body, -1) {
+		if budget <= 0 {
+			log.Debug("pdf streams inflated past the limit, skipping the rest", "url", currLink)
+			break
+		}
+		if !pdfFlateRegex.Match(pdfStreamDict(body, loc[0]+2)) {
+			continue
+		}
+		zr, err := zlib.NewReader(bytes.NewReader(body[loc[1]:]))
+		if err != nil {
+			continue
+		}
+		// the stream ends at "endstream", reading past the end of the compressed data fails
+		// with an error that can be ignored
+		inflated, _ := io.ReadAll(io.LimitReader(zr, budget))
+		zr.Close()
+		budget -= int64(len(inflated))
+		if len(inflated) > 0 {
+			sections = append(sections, inflated)
+		}
+	}
+
+	var (
+		raw   []string
+		title string
+	)
+	for _, s := range sections {
+		for _, m := range pdfURIRegex.FindAllSubmatch(s, -1) {
+			raw = append(raw, unescapePDFString(m[1]))
+		}
+		if m := pdfTitleRegex.FindSubmatch(s); m != nil && title == "" {
+			title = unescapePDFString(m[1])
+		}
+	}
+
+	res := ContentResult{Links: resolveLinks(currLink, raw)}
+	if title != "" {
+		res.Meta = map[string]string{"title": title}
+	}
+	return res
+}
+
+// Returns the dictionary of a stream that ends before end, including nested dictionaries such
+// as its /DecodeParms, or nil if it is not well formed.
+func pdfStreamDict(body []byte, end int) []byte {
+	depth := 0
+	for i := end - 1; i > 0; i-- {
+		switch {
+		case body[i] == '>' && body[i-1] == '>':
+			depth++
+			i--
+		case body[i] == '<' && body[i-1] == '<':
+			depth--
+			i--
+			if depth == 0 {
+				return body[i:end]
+			}
+		}
+	}
+	return nil
+}
+
+// Unescapes the backslash escapes of a PDF literal string, e.g. \( and \).
+func unescapePDFString(b []byte) string {
+	var sb strings.Builder
+	for i := 0; i < len(b); i++ {
+		if b[i] == '\\' && i+1 < len(b) {
+			i++
+			switch b[i] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case 'r', '\n':
+			default:
+				sb.WriteByte(b[i])
+			}
+			continue
+		}
+		sb.WriteByte(b[i])
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// Resolves the links against the current link as ExtractLinks does, removing fragments, and
+// returns them sorted and unique.
+func resolveLinks(currLink string, raw []string) []string {
+	base, err := url.Parse(currLink)
+	if err != nil {
+		return nil
+	}
+
+	linkSet := make(map[string]struct{})
+	for _, r := range raw {
+		u, err := url.Parse(strings.TrimSpace(r))
+		if err != nil || r == "" {
+			continue
+		}
+		u = base.ResolveReference(u)
+		u.Fragment = ""
+		linkSet[u.String()] = struct{}{}
+	}
+
+	links := make([]string, 0, len(linkSet))
+	for k := range linkSet {
+		links = append(links, k)
+	}
+	slices.Sort(links)
+	return links
+}
@@ -0,0 +1,221 @@
+package gocrawler
+
+import (
+	"bytes"
+	"compress/zlib"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// Returns the function name of the handler, as func values cannot be compared.
+func handlerName(h ContentHandler) string {
+	if h == nil {
+		return "<nil>"
+	}
+	name := runtime.FuncForPC(reflect.ValueOf(h).Pointer()).Name()
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+func TestContentHandlersLookup(t *testing.T) {
+	handlers := ContentHandlers{
+		"text/html":        HTMLHandler,
+		"application/json": JSONHandler,
+		"application/xml":  XMLHandler,
+	}
+	tests := []struct {
+		contentType string
+		exp         ContentHandler
+	}{
+		{"text/html; charset=utf-8", HTMLHandler},
+		{"Application/JSON", JSONHandler},
+		// structured syntax suffixes fall back to the handler of the suffix
+		{"application/ld+json", JSONHandler},
+		{"application/vnd.api+json; charset=utf-8", JSONHandler},
+		{"image/svg+xml", XMLHandler},
+		{"application/problem+yaml", nil},
+		{"text/csv", nil},
+		{"", nil},
+		{"not a media type", nil},
+	}
+	for _, tt := range tests {
+		handler, ok := handlers.Lookup(tt.contentType)
+		if ok != (tt.exp != nil) || handlerName(handler) != handlerName(tt.exp) {
+			t.Errorf("Expected %q to be handled by %s, got %s", tt.contentType, handlerName(tt.exp), handlerName(handler))
+		}
+	}
+
+	// a handler of its own takes precedence over the handler of the suffix
+	handlers["application/ld+json"] = TextHandler
+	if handler, _ := handlers.Lookup("application/ld+json"); handlerName(handler) != "TextHandler" {
+		t.Errorf("Expected TextHandler, got %s", handlerName(handler))
+	}
+}
+
+func TestHTMLHandler(t *testing.T) {
+	c := &Client{le: DefaultLinkExtractor}
+	body := []byte(`<html><head><title> A
+		page </title></head><body><a href="/b">B</a><a href="https://c.com/#top">C</a></body></html>`)
+
+	res := HTMLHandler(c, "https://a.com/", body)
+	if exp := []string{"https://a.com/b", "https://c.com/"}; !reflect.DeepEqual(res.Links, exp) {
+		t.Errorf("Expected %v, got %v", exp, res.Links)
+	}
+	if exp := map[string]string{"title": "A page"}; !reflect.DeepEqual(res.Meta, exp) {
+		t.Errorf("Expected %v, got %v", exp, res.Meta)
+	}
+}
+
+func TestXMLHandler(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		links []string
+		meta  map[string]string
+	}{
+		{
+			"sitemap",
+			`<?xml version="1.0" encoding="ISO-8859-1"?><urlset><url><loc> https://a.com/x </loc></url><url><loc>/y</loc></url></urlset>`,
+			[]string{"https://a.com/x", "https://a.com/y"},
+			map[string]string{"format": "sitemap"},
+		},
+		{
+			"sitemap index",
+			`<sitemapindex><sitemap><loc>https://a.com/pages.xml</loc></sitemap></sitemapindex>`,
+			[]string{"https://a.com/pages.xml"},
+			map[string]string{"format": "sitemap_index"},
+		},
+		{
+			"rss",
+			`<rss><channel><title>Feed</title><link>https://a.com/</link><item><title>Post</title><link>https://a.com/post</link></item></channel></rss>`,
+			[]string{"https://a.com/", "https://a.com/post"},
+			map[string]string{"format": "rss", "title": "Feed"},
+		},
+		{
+			"atom",
+			`<feed><title>Feed</title><link rel="self" href="/feed.xml"/><entry><link href="/post"/><link rel="related" href="https://b.com/"/></entry></feed>`,
+			[]string{"https://a.com/post", "https://b.com/"},
+			map[string]string{"format": "atom", "title": "Feed"},
+		},
+		{
+			"other",
+			`<note><link>https://a.com/</link></note>`,
+			[]string{},
+			map[string]string{"format": "xml"},
+		},
+	}
+	for _, tt := range tests {
+		res := XMLHandler(nil, "https://a.com/feed.xml", []byte(tt.body))
+		if !reflect.DeepEqual(res.Links, tt.links) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.links, res.Links)
+		}
+		if !reflect.DeepEqual(res.Meta, tt.meta) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.meta, res.Meta)
+		}
+	}
+}
+
+func TestJSONHandler(t *testing.T) {
+	body := []byte(`{"next": "/api/items?page=2", "items": [{"url": "https://b.com/x#y"}, {"name": "not a link", "path": "/"}], "n": 1}`)
+	exp := []string{"https://a.com/api/items?page=2", "https://b.com/x"}
+	if got := JSONHandler(nil, "https://a.com/api/items", body).Links; !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %v, got %v", exp, got)
+	}
+	if got := JSONHandler(nil, "https://a.com/", []byte(`{"broken"`)).Links; got != nil {
+		t.Errorf("Expected no links from invalid json, got %v", got)
+	}
+}
+
+func TestTextHandler(t *testing.T) {
+	body := []byte(`See https://a.com/x. Or (https://b.com/y), "https://c.com/z"!
+		Also https://en.wikipedia.org/wiki/Go_(programming_language) and
+		(see https://en.wikipedia.org/wiki/Go_(game)).`)
+	exp := []string{
+		"https://a.com/x",
+		"https://b.com/y",
+		"https://c.com/z",
+		"https://en.wikipedia.org/wiki/Go_(game)",
+		"https://en.wikipedia.org/wiki/Go_(programming_language)",
+	}
+	if got := TextHandler(nil, "https://a.com/", body).Links; !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %v, got %v", exp, got)
+	}
+}
+
+// Compresses the content, which is padded so that it is not stored as is in short streams.
+func deflate(t *testing.T, b []byte) []byte {
+	t.Helper()
+	b = append(b, bytes.Repeat([]byte(" "), 256)...)
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	if _, err := zw.Write(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(buf.Bytes(), b[:min(len(b), 16)]) {
+		t.Fatalf("Expected the content to be compressed")
+	}
+	return buf.Bytes()
+}
+
+func TestPDFHandler(t *testing.T) {
+	annot := []byte(`<< /Type /Annot /A << /S /URI /URI (https://a.com/compressed) >> >>`)
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.7\n1 0 obj\n<< /Title (A \\(draft\\) report) >>\nendobj\n")
+	pdf.WriteString("2 0 obj\n<< /Type /Annot /A << /S /URI /URI (https://a.com/plain) >> >>\nendobj\n")
+	// a compressed object stream, with nested dictionaries
+	pdf.WriteString("3 0 obj\n<< /Type /ObjStm /Filter /FlateDecode /DecodeParms << /Predictor 1 >> >>\nstream\n")
+	pdf.Write(deflate(t, annot))
+	pdf.WriteString("\nendstream\nendobj\n")
+	// zlib data in a stream that is not FlateDecode is not inflated
+	pdf.WriteString("4 0 obj\n<< /Filter /LZWDecode >>\nstream\n")
+	pdf.Write(deflate(t, []byte(`/URI (https://a.com/not-flate)`)))
+	pdf.WriteString("\nendstream\nendobj\n")
+
+	res := PDFHandler(nil, "https://a.com/report.pdf", pdf.Bytes())
+	if exp := []string{"https://a.com/compressed", "https://a.com/plain"}; !reflect.DeepEqual(res.Links, exp) {
+		t.Errorf("Expected %v, got %v", exp, res.Links)
+	}
+	if exp := map[string]string{"title": "A (draft) report"}; !reflect.DeepEqual(res.Meta, exp) {
+		t.Errorf("Expected %v, got %v", exp, res.Meta)
+	}
+}
+
+func TestPDFHandlerInflateLimit(t *testing.T) {
+	// the streams of a document are inflated up to maxPDFInflatedBytes in total, so the links of
+	// a stream after a stream that inflates to the limit are not extracted
+	var pdf bytes.Buffer
+	for _, content := range [][]byte{
+		bytes.Repeat([]byte(" "), maxPDFInflatedBytes),
+		[]byte(`/URI (https://a.com/over-limit)`),
+	} {
+		pdf.WriteString("1 0 obj\n<< /Filter [/FlateDecode] >>\nstream\n")
+		pdf.Write(deflate(t, content))
+		pdf.WriteString("\nendstream\nendobj\n")
+	}
+
+	if got := PDFHandler(nil, "https://a.com/bomb.pdf", pdf.Bytes()).Links; len(got) != 0 {
+		t.Errorf("Expected no links past the inflate limit, got %v", got)
+	}
+}
+
+func TestTrimURLPunctuation(t *testing.T) {
+	tests := []struct {
+		s, exp string
+	}{
+		{"https://a.com/x.", "https://a.com/x"},
+		{"https://a.com/x),", "https://a.com/x"},
+		{"https://a.com/x_(y)", "https://a.com/x_(y)"},
+		{"https://a.com/x_(y))", "https://a.com/x_(y)"},
+		{"https://a.com/x_(y).", "https://a.com/x_(y)"},
+		{"https://a.com/?q=\"a\"", "https://a.com/?q=\"a"},
+	}
+	for _, tt := range tests {
+		if got := trimURLPunctuation(tt.s); got != tt.exp {
+			t.Errorf("Expected %q, got %q", tt.exp, got)
+		}
+	}
+}
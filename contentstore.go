@@ -0,0 +1,163 @@
+package gocrawler
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var ErrContentNotFound = errors.New("content not found")
+
+// ContentStore stores page bodies addressed by the SHA-256 hash of the body, which means that
+// identical pages are only stored once. Implementations must be safe for concurrent use.
+type ContentStore interface {
+	// Put stores the body and returns its hash
+	Put(body []byte) (string, error)
+	// Get returns the body with the hash, or ErrContentNotFound if it was not stored
+	Get(hash string) ([]byte, error)
+}
+
+// ContentHash returns the hex encoded SHA-256 hash of the body, as used by the content stores.
+func ContentHash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// Content returns the body of the page from the content store.
+func (c *Client) Content(pi PageInfo) ([]byte, error) {
+	if pi.ContentHash == "" {
+		return nil, ErrContentNotFound
+	}
+	return c.ContentStore.Get(pi.ContentHash)
+}
+
+// MemoryStore keeps bodies in memory, this is the default store of the crawler.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	content map[string][]byte
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{content: make(map[string][]byte)}
+}
+
+func (s *MemoryStore) Put(body []byte) (string, error) {
+	hash := ContentHash(body)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.content[hash]; !ok {
+		s.content[hash] = body
+	}
+	return hash, nil
+}
+
+func (s *MemoryStore) Get(hash string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	body, ok := s.content[hash]
+	if !ok {
+		return nil, ErrContentNotFound
+	}
+	return body, nil
+}
+
+// NullStore discards all bodies, only the hash is kept on the page info.
+type NullStore struct{}
+
+func (NullStore) Put(body []byte) (string, error) {
+	return ContentHash(body), nil
+}
+
+func (NullStore) Get(hash string) ([]byte, error) {
+	return nil, ErrContentNotFound
+}
+
+// FSStore writes gzip-compressed bodies to a folder, named after their hash and sharded into
+// sub-folders by the first 2 characters of the hash (e.g. <dir>/ab/abcd...ef.gz).
+type FSStore struct {
+	dir string
+}
+
+func NewFSStore(dir string) *FSStore {
+	return &FSStore{dir: dir}
+}
+
+// Reports whether the hash is a hex encoded SHA-256 hash as returned by ContentHash, so that
+// hashes from elsewhere (e.g. a report) cannot refer to files outside of the folder.
+func isContentHash(hash string) bool {
+	if len(hash) != sha256.Size*2 {
+		return false
+	}
+	for _, r := range hash {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *FSStore) path(hash string) string {
+	return filepath.Join(s.dir, hash[:2], hash+".gz")
+}
+
+func (s *FSStore) Put(body []byte) (string, error) {
+	hash := ContentHash(body)
+	path := s.path(hash)
+	if _, err := os.Stat(path); err == nil {
+		return hash, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+
+	// write to a temporary file first so that concurrent writes never leave a partial file
+	f, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+
+	zw := gzip.NewWriter(f)
+	if _, err := zw.Write(body); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+
+	return hash, os.Rename(f.Name(), path)
+}
+
+func (s *FSStore) Get(hash string) ([]byte, error) {
+	if !isContentHash(hash) {
+		return nil, ErrContentNotFound
+	}
+
+	f, err := os.Open(s.path(hash))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrContentNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	return io.ReadAll(zr)
+}
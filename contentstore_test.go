@@ -0,0 +1,132 @@
+package gocrawler_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yusufaine/gocrawler"
+)
+
+func TestContentStores(t *testing.T) {
+	body := []byte("<html><body>hello</body></html>")
+	hash := gocrawler.ContentHash(body)
+	missing := gocrawler.ContentHash([]byte("missing"))
+
+	stores := map[string]gocrawler.ContentStore{
+		"memory": gocrawler.NewMemoryStore(),
+		"fs":     gocrawler.NewFSStore(t.TempDir()),
+	}
+	for name, store := range stores {
+		// identical bodies are only stored once under the same hash
+		for i := 0; i < 2; i++ {
+			got, err := store.Put(body)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if got != hash {
+				t.Errorf("%s: expected hash %s, got %s", name, hash, got)
+			}
+		}
+
+		got, err := store.Get(hash)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !bytes.Equal(got, body) {
+			t.Errorf("%s: expected %q, got %q", name, body, got)
+		}
+		if _, err := store.Get(missing); !errors.Is(err, gocrawler.ErrContentNotFound) {
+			t.Errorf("%s: expected ErrContentNotFound, got %v", name, err)
+		}
+	}
+}
+
+func TestFSStoreLayout(t *testing.T) {
+	dir := t.TempDir()
+	store := gocrawler.NewFSStore(dir)
+	body := bytes.Repeat([]byte("compressible "), 100)
+	hash, err := store.Put(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// bodies are gzipped and sharded by the first 2 characters of the hash
+	f, err := os.Open(filepath.Join(dir, hash[:2], hash+".gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, body) {
+		t.Errorf("Expected the gzipped file to contain the body")
+	}
+
+	// no temporary files are left behind
+	entries, err := os.ReadDir(filepath.Join(dir, hash[:2]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected 1 file in the shard, got %d", len(entries))
+	}
+
+	if _, err := store.Get("a"); !errors.Is(err, gocrawler.ErrContentNotFound) {
+		t.Errorf("Expected ErrContentNotFound for a short hash, got %v", err)
+	}
+}
+
+func TestFSStoreInvalidHash(t *testing.T) {
+	// a gzipped file outside of the store that a hash with a path in it would resolve to
+	dir := t.TempDir()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte("secret"))
+	zw.Close()
+	if err := os.WriteFile(filepath.Join(dir, "secret.gz"), buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	store := gocrawler.NewFSStore(filepath.Join(dir, "a", "store"))
+	hash, err := store.Put([]byte("body"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, h := range []string{
+		"../secret",
+		strings.ToUpper(hash),
+		hash + "0",
+		hash[:63] + "g",
+	} {
+		if _, err := store.Get(h); !errors.Is(err, gocrawler.ErrContentNotFound) {
+			t.Errorf("Expected ErrContentNotFound for %q, got %v", h, err)
+		}
+	}
+}
+
+func TestNullStore(t *testing.T) {
+	var store gocrawler.NullStore
+	body := []byte("discarded")
+	hash, err := store.Put(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != gocrawler.ContentHash(body) {
+		t.Errorf("Expected the hash of the body, got %s", hash)
+	}
+	if _, err := store.Get(hash); !errors.Is(err, gocrawler.ErrContentNotFound) {
+		t.Errorf("Expected ErrContentNotFound, got %v", err)
+	}
+}
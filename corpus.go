@@ -0,0 +1,58 @@
+package gocrawler
+
+import (
+	"github.com/charmbracelet/log"
+	"github.com/yusufaine/gocrawler/readability"
+)
+
+// ArticleInfo is the title, word count and reading time of the main text of a page, the text
+// itself is only written to Config.Corpus so that it is not kept in memory for the whole crawl.
+type ArticleInfo struct {
+	Title          string `json:"title,omitempty"`
+	WordCount      int    `json:"word_count"`
+	ReadingSeconds int    `json:"reading_seconds"`
+}
+
+func newArticleInfo(a *readability.Article) *ArticleInfo {
+	if a == nil {
+		return nil
+	}
+	return &ArticleInfo{Title: a.Title, WordCount: a.WordCount, ReadingSeconds: a.ReadingSeconds}
+}
+
+// CorpusDocument is a line of the text corpus, the main text of a page with its metadata.
+type CorpusDocument struct {
+	URL            string `json:"url"`
+	Title          string `json:"title,omitempty"`
+	Lang           string `json:"lang,omitempty"`
+	WordCount      int    `json:"word_count"`
+	ReadingSeconds int    `json:"reading_seconds"`
+	Text           string `json:"text"`
+}
+
+// Writes the main text of the page to the corpus, if any, in the order that pages are crawled.
+// Pages without words and duplicates of pages crawled before them are left out. The title and
+// language are taken from the page metadata if it was extracted. Like emit, this must not be
+// called while holding any of the crawler's mutexes.
+func (c *Client) writeCorpus(link string, a *readability.Article, pi *PageInfo) {
+	if c.corpus == nil || a == nil || a.WordCount == 0 || pi.DuplicateOf != "" {
+		return
+	}
+
+	doc := CorpusDocument{
+		URL:            link,
+		Title:          a.Title,
+		WordCount:      a.WordCount,
+		ReadingSeconds: a.ReadingSeconds,
+		Text:           a.Text,
+	}
+	if pi.PageMeta != nil {
+		doc.Lang = pi.PageMeta.Lang
+		if pi.PageMeta.Title != "" {
+			doc.Title = pi.PageMeta.Title
+		}
+	}
+	if err := c.corpus.write(doc); err != nil {
+		log.Error("unable to write corpus document", "url", link, "error", err)
+	}
+}
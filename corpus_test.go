@@ -0,0 +1,68 @@
+package gocrawler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWriteCorpus(t *testing.T) {
+	var corpus bytes.Buffer
+	c := New(context.Background(), &Config{
+		MaxRetries:       1,
+		Timeout:          5 * time.Second,
+		ProxyURL:         &url.URL{},
+		Corpus:           &corpus,
+		DetectDuplicates: true,
+		ExtractPageMeta:  true,
+	}, nil, DefaultLinkExtractor)
+
+	const article = `<p>A paragraph that is long enough to be scored as the main text, with commas, of the page.</p>`
+	pages := []struct {
+		link, body string
+	}{
+		{"https://a.com/", `<html lang="en"><title>A</title><body>` + article + `</body></html>`},
+		{"https://a.com/copy", `<html lang="en"><title>A</title><body>` + article + `</body></html>`},
+		{"https://a.com/empty", `<html><body><img src="a.png"></body></html>`},
+	}
+	header := http.Header{"Content-Type": {"text/html"}}
+	for _, p := range pages {
+		c.updatePageInfo(0, p.link, "", []byte(p.body), false, nil, header, time.Now(), TransferInfo{})
+	}
+
+	// only the page with text is in the corpus, its duplicate and the page without words are not
+	var docs []CorpusDocument
+	dec := json.NewDecoder(&corpus)
+	for dec.More() {
+		var doc CorpusDocument
+		if err := dec.Decode(&doc); err != nil {
+			t.Fatal(err)
+		}
+		docs = append(docs, doc)
+	}
+	if len(docs) != 1 {
+		t.Fatalf("Expected 1 document in the corpus, got %+v", docs)
+	}
+	expDoc := CorpusDocument{
+		URL:            "https://a.com/",
+		Title:          "A",
+		Lang:           "en",
+		WordCount:      18,
+		ReadingSeconds: 5,
+		Text:           "A paragraph that is long enough to be scored as the main text, with commas, of the page.",
+	}
+	if docs[0] != expDoc {
+		t.Errorf("Expected %+v, got %+v", expDoc, docs[0])
+	}
+
+	// the page info only has the word count and reading time of the text
+	exp := &ArticleInfo{Title: "A", WordCount: 18, ReadingSeconds: 5}
+	if got := c.VisitedPageInfo["https://a.com/"].Article; !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %+v, got %+v", exp, got)
+	}
+}
@@ -12,7 +12,12 @@ import (
 	"time"
 
 	"github.com/charmbracelet/log"
+	"github.com/yusufaine/gocrawler/fingerprint"
+	"github.com/yusufaine/gocrawler/hostrule"
 	"github.com/yusufaine/gocrawler/internal/rhttp"
+	"github.com/yusufaine/gocrawler/readability"
+	"github.com/yusufaine/gocrawler/scope"
+	"github.com/yusufaine/gocrawler/scrape"
 	"golang.org/x/time/rate"
 )
 
@@ -21,14 +26,36 @@ type Client struct {
 	hc  *rhttp.Client
 	le  LinkExtractor
 	rl  *rate.Limiter
-	rm  []ResponseMatcher
-
-	MaxDepth        int
-	NetMutex        sync.RWMutex
-	PageMutex       sync.RWMutex
-	HostBlacklist   map[string]struct{}
-	VisitedNetInfo  map[string][]NetworkInfo
-	VisitedPageInfo map[string]PageInfo
+	rm  []Matcher
+
+	corpus            *lineWriter
+	duplicates        *dupState
+	events            *lineWriter
+	extractText       bool
+	handlers          ContentHandlers
+	maxBodyBytes      int64
+	recorder          ExchangeRecorder
+	maxRedirects      int
+	pageMeta          bool
+	sameHostRedirects bool
+	scope             *scope.Scope
+	scrapeRules       *scrape.Rules
+	skipNearDups      bool
+	traps             TrapConfig
+	trapState         *trapState
+	recrawl           *recrawlState
+
+	MaxDepth         int
+	AttemptMutex     sync.RWMutex
+	NetMutex         sync.RWMutex
+	PageMutex        sync.RWMutex
+	Attempts         map[string]AttemptInfo // every URL the crawler tried to visit, guarded by AttemptMutex
+	HostAllowlist    hostrule.Rules
+	HostBlacklist    hostrule.Rules
+	VisitedNetInfo   map[string][]NetworkInfo
+	VisitedPageInfo  map[string]PageInfo
+	VisitedRedirects map[string][]RedirectHop // keyed by the requested URL, guarded by PageMutex
+	ContentStore     ContentStore
 }
 
 // New creates a new crawler client using the context to allow for cancellation, the crawler
@@ -36,30 +63,70 @@ type Client struct {
 //
 // Note that the ordering of the response matchers matter, the first matcher to return
 // false will cause the link to be skipped.
-func New(ctx context.Context, config *Config, rm []ResponseMatcher, le LinkExtractor) *Client {
+func New(ctx context.Context, config *Config, rm []Matcher, le LinkExtractor) *Client {
 	if len(rm) == 0 {
-		rm = []ResponseMatcher{IsNoopResponse}
+		rm = []Matcher{ResponseMatcher(IsNoopResponse)}
 		log.Warn("no response matchers supplied, accepting all responses")
 	}
 
-	retryClient := rhttp.New(
+	opts := []rhttp.RHTTPOption{
 		rhttp.WithBackoffPolicy(rhttp.ExponentialBackoff),
 		rhttp.WithMaxRetries(config.MaxRetries),
 		rhttp.WithRetryPolicy(rhttp.DefaultRetry),
 		rhttp.WithTimeout(config.Timeout),
 		rhttp.WithProxy(config.ProxyURL),
-	)
+		rhttp.WithCheckRedirect(noFollowRedirect),
+	}
+	if config.HARRecorder != nil {
+		opts = append(opts, rhttp.WithHARRecorder(config.HARRecorder))
+	}
+	retryClient := rhttp.New(opts...)
+
+	maxRedirects := config.MaxRedirects
+	if maxRedirects == 0 {
+		maxRedirects = defaultMaxRedirects
+	}
+
+	nearDupDistance := config.NearDupDistance
+	if nearDupDistance == 0 {
+		nearDupDistance = fingerprint.DefaultDistance
+	}
+
+	store := config.ContentStore
+	if store == nil {
+		store = NewMemoryStore()
+	}
 
 	c := &Client{
-		ctx:             ctx,
-		hc:              retryClient,
-		le:              le,
-		rl:              rate.NewLimiter(rate.Limit(config.MaxRPS), 1),
-		rm:              rm,
-		MaxDepth:        config.MaxDepth - 1,
-		HostBlacklist:   config.BlacklistHosts,
-		VisitedNetInfo:  make(map[string][]NetworkInfo),
-		VisitedPageInfo: make(map[string]PageInfo),
+		ctx:               ctx,
+		duplicates:        newDupState(config.DetectDuplicates || config.SkipNearDups, nearDupDistance),
+		hc:                retryClient,
+		le:                le,
+		rl:                rate.NewLimiter(rate.Limit(config.MaxRPS), 1),
+		rm:                rm,
+		corpus:            newLineWriter(config.Corpus),
+		events:            newLineWriter(config.Events),
+		extractText:       config.ExtractText || config.Corpus != nil,
+		handlers:          config.ContentHandlers,
+		maxBodyBytes:      config.MaxBodyBytes,
+		recorder:          config.Recorder,
+		maxRedirects:      maxRedirects,
+		pageMeta:          config.ExtractPageMeta,
+		sameHostRedirects: config.SameHostRedirects,
+		scope:             config.Scope,
+		scrapeRules:       config.ScrapeRules,
+		skipNearDups:      config.SkipNearDups,
+		traps:             config.Traps,
+		trapState:         newTrapState(),
+		recrawl:           newRecrawlState(config.Previous, config.SkipUnchanged),
+		MaxDepth:          config.MaxDepth - 1,
+		HostAllowlist:     config.AllowHosts,
+		HostBlacklist:     config.BlacklistHosts,
+		Attempts:          make(map[string]AttemptInfo),
+		VisitedNetInfo:    make(map[string][]NetworkInfo),
+		VisitedPageInfo:   make(map[string]PageInfo),
+		VisitedRedirects:  make(map[string][]RedirectHop),
+		ContentStore:      store,
 	}
 
 	return c
@@ -71,16 +138,25 @@ func New(ctx context.Context, config *Config, rm []ResponseMatcher, le LinkExtra
 func (c *Client) Crawl(ctx context.Context, currDepth int, currLink, parent string) {
 
 	// sanity check to ensure crawler does not re-visits the same link
-	c.PageMutex.RLock()
-	_, ok := c.VisitedPageInfo[currLink]
-	c.PageMutex.RUnlock()
-	if ok {
+	if c.isVisited(currLink) {
 		return
 	}
 
 	log.Info("visiting", "depth", currDepth, "link", currLink)
 
-	links := c.storeBodyExtractLinks(currLink, parent, currDepth)
+	// pages of the previous crawl that are known to be unchanged are not requested again
+	links, ok := c.carryForward(currLink, parent, currDepth)
+	if !ok {
+		links = c.storeBodyExtractLinks(currLink, parent, currDepth)
+	}
+	links = c.prioritise(links)
+
+	// outgoing links belong to the page that the link was redirected to, if any
+	c.PageMutex.RLock()
+	if hops, ok := c.VisitedRedirects[currLink]; ok {
+		currLink = hops[len(hops)-1].Location
+	}
+	c.PageMutex.RUnlock()
 
 	// crawl all outgoing links concurrently
 	nextDepth := currDepth + 1
@@ -91,18 +167,23 @@ func (c *Client) Crawl(ctx context.Context, currDepth int, currLink, parent stri
 			defer wg.Done()
 			// Do not continue crawling if the nextDepth has exceeded the max depth
 			if nextDepth > c.MaxDepth {
+				c.RecordAttempt(nextLink, AttemptInfo{
+					Depth:   nextDepth,
+					Parent:  currLink,
+					Outcome: OutcomeDepthExceeded,
+				})
+				return
+			}
+
+			if c.isVisited(nextLink) {
 				return
 			}
 
-			c.PageMutex.RLock()
-			_, ok := c.VisitedPageInfo[nextLink]
-			c.PageMutex.RUnlock()
-			if ok {
+			if trap, ok := c.detectTrap(nextLink); ok {
+				c.recordTrap(nextLink, currLink, nextDepth, trap)
 				return
 			}
 
-			// ensure RPS is enforced
-			_ = c.rl.Wait(ctx)
 			c.Crawl(ctx, nextDepth, nextLink, currLink)
 		}(currLink, nextLink, nextDepth)
 	}
@@ -110,29 +191,46 @@ func (c *Client) Crawl(ctx context.Context, currDepth int, currLink, parent stri
 	log.Info("visited all links in the branch", "depth", currDepth, "branch", currLink)
 }
 
+// Checks if the link has been visited, either as a page, as the start of a redirect chain, or
+// as an attempt that failed or was skipped for reasons other than the depth.
+func (c *Client) isVisited(link string) bool {
+	c.PageMutex.RLock()
+	_, isPage := c.VisitedPageInfo[link]
+	_, isRedirect := c.VisitedRedirects[link]
+	c.PageMutex.RUnlock()
+	if isPage || isRedirect {
+		return true
+	}
+
+	c.AttemptMutex.RLock()
+	defer c.AttemptMutex.RUnlock()
+	ai, ok := c.Attempts[link]
+	return ok && ai.Outcome != OutcomeDepthExceeded
+}
+
 // Does the actual HTTP GET request and returns the response body if the response is
-// successful and the content type is text.
+// successful and the content type is text. Pages are stored under the final URL after
+// following redirects.
 func (c *Client) storeBodyExtractLinks(link, parent string, depth int) []string {
 	parsedUrl, err := url.Parse(link)
 	if err != nil {
+		c.RecordAttempt(link, AttemptInfo{
+			Depth:   depth,
+			Parent:  parent,
+			Outcome: OutcomeInvalidURL,
+			Error:   err.Error(),
+		})
 		log.Error("unable to parse url", "url", link, "error", err)
 		return nil
 	}
 
-	remoteAddrs, err := net.LookupIP(parsedUrl.Host)
-	if err != nil {
-		log.Error("unable to resolve host", "host", parsedUrl.Host, "error", err)
-		return nil
-	}
-
-	req, err := http.NewRequestWithContext(c.ctx, "GET", parsedUrl.String(), nil)
-	if err != nil {
-		log.Error("unable to create request", "url", parsedUrl.String(), "error", err)
-		return nil
-	}
-
 	reqStart := time.Now()
-	resp, err := c.hc.Do(req)
+	resp, hops, err := c.fetch(parsedUrl, parent, depth)
+	if len(hops) > 0 {
+		c.PageMutex.Lock()
+		c.VisitedRedirects[link] = hops
+		c.PageMutex.Unlock()
+	}
 	if err != nil {
 		if errors.Is(err, context.Canceled) {
 			return nil
@@ -143,56 +241,147 @@ func (c *Client) storeBodyExtractLinks(link, parent string, depth int) []string
 			c.VisitedPageInfo[link] = pi
 		}
 		c.PageMutex.Unlock()
+		c.recordError(link, parent, depth, err)
 		log.Error("unable to get response", "host", parsedUrl.Host, "error", err)
 		return nil
 	}
 	defer resp.Body.Close()
+	// kept before any matcher wraps the body, to count the bytes transferred
+	decodedBody, _ := rhttp.Decoded(resp.Body)
 
 	respTime := time.Since(reqStart)
 
+	// responses whose body is not read are still recorded
+	unreadExchange := &Exchange{
+		Response:  resp,
+		Truncated: resp.ContentLength != 0,
+		FetchedAt: reqStart,
+		Depth:     depth,
+		Parent:    parent,
+	}
+
+	// the final URL is used for dedup as different links may redirect to the same page
+	finalUrl := resp.Request.URL
+	finalLink := finalUrl.String()
+	attempt := AttemptInfo{
+		Depth:      depth,
+		Parent:     parent,
+		Outcome:    OutcomeOK,
+		StatusCode: resp.StatusCode,
+	}
+	if resp.StatusCode >= 400 {
+		attempt.Outcome = OutcomeHTTPError
+	}
+	if finalLink != link {
+		attempt.FinalURL = finalLink
+		c.PageMutex.Lock()
+		pi, ok := c.VisitedPageInfo[finalLink]
+		if ok {
+			pi.Depth = min(pi.Depth, depth)
+			c.VisitedPageInfo[finalLink] = pi
+		}
+		c.PageMutex.Unlock()
+		if ok {
+			c.RecordAttempt(link, attempt)
+			c.recordExchange(unreadExchange)
+			log.Debug("redirected to visited page", "link", link, "final", finalLink)
+			return nil
+		}
+	}
+
+	// the page has not changed since the previous crawl, so the body was not sent
+	if prev, ok := c.previousPage(finalLink); ok && resp.StatusCode == http.StatusNotModified {
+		c.RecordAttempt(link, attempt)
+		c.recordExchange(unreadExchange)
+		if remoteAddrs, err := net.LookupIP(finalUrl.Hostname()); err == nil {
+			c.updateNetInfo(finalUrl, remoteAddrs, respTime, TransferInfo{})
+		}
+		return c.storeNotModified(finalLink, parent, depth, prev, hops, reqStart)
+	}
+
 	// if any of the response filters return false, skip the link
-	for _, f := range c.rm {
-		if !f(resp) {
+	for _, rm := range c.rm {
+		if ok, rejectedBy := rm.MatchResponse(resp); !ok {
+			if attempt.Outcome == OutcomeOK {
+				attempt.Outcome = OutcomeFiltered
+			}
+			attempt.Matcher = rejectedBy
+			c.RecordAttempt(link, attempt)
+			c.recordExchange(unreadExchange)
 			return nil
 		}
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	remoteAddrs, err := net.LookupIP(finalUrl.Hostname())
 	if err != nil {
+		c.recordError(link, parent, depth, err)
+		log.Error("unable to resolve host", "host", finalUrl.Host, "error", err)
+		return nil
+	}
+
+	body, truncated, err := c.readBody(resp.Body)
+	if err != nil {
+		c.recordError(link, parent, depth, err)
 		log.Error("unable to read response body", "url", link, "error", err)
 		return nil
 	}
+	if truncated {
+		log.Warn("response body exceeded max size, truncating", "url", link, "max_bytes", c.maxBodyBytes)
+	}
+	c.RecordAttempt(link, attempt)
+
+	transfer := TransferInfo{CompressedBytes: int64(len(body)), DecompressedBytes: int64(len(body))}
+	if decodedBody != nil {
+		transfer = TransferInfo{
+			ContentEncoding:   decodedBody.Encoding(),
+			CompressedBytes:   decodedBody.WireBytes(),
+			DecompressedBytes: decodedBody.DecodedBytes(),
+		}
+	}
 
 	var wg sync.WaitGroup
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		c.updateNetInfo(parsedUrl, remoteAddrs, respTime)
+		c.updateNetInfo(finalUrl, remoteAddrs, respTime, transfer)
 	}()
 
 	var links []string
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		links = c.updatePageInfo(depth, link, parent, body)
+		links = c.updatePageInfo(depth, finalLink, parent, body, truncated, hops, resp.Header, reqStart, transfer)
 	}()
 	wg.Wait()
 
+	c.recordExchange(&Exchange{
+		Response:  resp,
+		Body:      body,
+		Truncated: truncated,
+		FetchedAt: reqStart,
+		Depth:     depth,
+		Parent:    parent,
+		Links:     links,
+	})
+
 	return links
 }
 
 // Collects/updates the network info for the current link which includes the total response time,
-// the remote IP addresses, the location of the remote IP addresses, and the visited paths.
-func (c *Client) updateNetInfo(parsedUrl *url.URL, remoteAddrs []net.IP, respTime time.Duration) {
+// the remote IP addresses, the location of the remote IP addresses, the visited paths, and the
+// bytes of the bodies transferred.
+func (c *Client) updateNetInfo(parsedUrl *url.URL, remoteAddrs []net.IP, respTime time.Duration, transfer TransferInfo) {
 	c.NetMutex.Lock()
-	defer c.NetMutex.Unlock()
 	if infos, ok := c.VisitedNetInfo[parsedUrl.Host]; ok {
 		for i, info := range infos {
 			if _, ok := info.VisitedPathSet[parsedUrl.Path]; !ok {
 				info.VisitedPathSet[parsedUrl.Path] = struct{}{}
 			}
 
+			info.RequestCount++
 			info.TotalResponseTimeMs += respTime.Milliseconds()
+			info.CompressedBytes += transfer.CompressedBytes
+			info.DecompressedBytes += transfer.DecompressedBytes
 			c.VisitedNetInfo[parsedUrl.Host][i] = info
 		}
 	} else {
@@ -212,10 +401,18 @@ func (c *Client) updateNetInfo(parsedUrl *url.URL, remoteAddrs []net.IP, respTim
 			{
 				RemoteIPInfo:        remoteIpInfo,
 				VisitedPathSet:      map[string]struct{}{parsedUrl.Path: {}},
+				RequestCount:        1,
 				TotalResponseTimeMs: respTime.Milliseconds(),
+				CompressedBytes:     transfer.CompressedBytes,
+				DecompressedBytes:   transfer.DecompressedBytes,
 			},
 		}
+		c.NetMutex.Unlock()
+
+		c.emit(Event{Type: EventHost, Host: parsedUrl.Host, RemoteIPInfo: remoteIpInfo})
+		return
 	}
+	c.NetMutex.Unlock()
 }
 
 // Resolves the remote IP address to its location and AS number. This uses the ipapi.co API.
@@ -248,28 +445,158 @@ func (c *Client) resolveIPInfo(ip string) (string, string, error) {
 	return ipInfo.ASNumber, ipInfo.Country + ", " + ipInfo.Region, nil
 }
 
-// Collects/updates the page info for the current link which includes the response body, the
-// depth, the outgoing links, and the parent link. The outgoing links are extracted by the
-// LinkExtractor, and the depth is the lowest/shallowest depth.
-func (c *Client) updatePageInfo(currDepth int, currLink, parent string, body []byte) []string {
-	links := c.le(c, currLink, body)
+// Collects/updates the page info for the current link which includes the hash of the response
+// body, the depth, the outgoing links, the parent link, and the redirects taken to reach it. The
+// outgoing links are extracted by the LinkExtractor, and the depth is the lowest/shallowest depth.
+// The <a> tags of HTML pages are counted per outgoing link, along with their anchor texts.
+// The validators and change history of the page are compared with the previous crawl, if any.
+// If page metadata is extracted, the canonical URL of the page is followed as well, and none of
+// the links are followed if the page is nofollow. The main text of HTML pages is extracted if
+// Config.ExtractText is set and written to Config.Corpus, and their structured data if
+// Config.ScrapeRules is set.
+func (c *Client) updatePageInfo(currDepth int, currLink, parent string, body []byte, truncated bool, hops []RedirectHop, header http.Header, fetchedAt time.Time, transfer TransferInfo) []string {
+	// responses of media types with a content handler are handled by it, the rest by the
+	// link extractor
+	var (
+		links []string
+		meta  map[string]string
+	)
+	contentType := header.Get("Content-Type")
+	text, charsetName := toUTF8(body, contentType)
+	if handler, ok := c.handlers.Lookup(contentType); ok {
+		res := handler(c, currLink, text)
+		links, meta = res.Links, res.Meta
+	} else {
+		links = c.le(c, currLink, text)
+	}
+	pageMeta := c.parsePageMeta(currLink, text, header)
+	links = c.filterLinks(withCanonical(links, currLink, pageMeta), currLink, currDepth+1)
+	var anchors map[string]LinkAnchors
+	if isHTML(contentType) {
+		anchors = linkAnchors(currLink, text, links)
+	}
+
+	// the body is stored as it was received
+	contentHash, err := c.ContentStore.Put(body)
+	if err != nil {
+		log.Error("unable to store content", "url", currLink, "error", err)
+	}
+	simhash := fingerprint.SimHash(fingerprint.Text(text))
+	dupOf := c.findDuplicate(currLink, contentHash, simhash)
+	var article *readability.Article
+	if c.extractText && isHTML(contentType) {
+		a := readability.Extract(text)
+		article = &a
+	}
+	var scraped map[string]any
+	if c.scrapeRules != nil && isHTML(contentType) {
+		scraped = c.scrapeRules.Scrape(currLink, text)
+	}
 
 	// mark the current URL as visited
 	c.PageMutex.Lock()
-	defer c.PageMutex.Unlock()
 
 	// ensures that the depth is always the lowest
 	if pi, ok := c.VisitedPageInfo[currLink]; ok {
 		pi.Depth = min(pi.Depth, currDepth)
 		c.VisitedPageInfo[currLink] = pi
+		c.PageMutex.Unlock()
 	} else {
-		c.VisitedPageInfo[currLink] = PageInfo{
-			Content: body,
-			Depth:   currDepth,
-			Links:   links,
-			Parent:  parent,
+		pi := PageInfo{
+			ContentHash: contentHash,
+			Depth:       currDepth,
+			Links:       links,
+			Anchors:     anchors,
+			Parent:      parent,
+			Redirects:   hops,
+			Truncated:   truncated,
+			ContentType: mediaType(contentType),
+			Charset:     charsetName,
+			Transfer:    transfer,
+			Metadata:    meta,
+			PageMeta:    pageMeta,
+			Article:     newArticleInfo(article),
+			Scraped:     scraped,
+			SimHash:     simhash,
+			DuplicateOf: dupOf,
+		}
+		c.trackChanges(&pi, currLink, header, fetchedAt)
+		c.VisitedPageInfo[currLink] = pi
+		c.PageMutex.Unlock()
+
+		c.emit(Event{Type: EventPage, URL: currLink, Page: &pi})
+		c.writeCorpus(currLink, article, &pi)
+
+		if pi.DuplicateOf != "" && c.skipNearDups {
+			log.Info("not following links of near-duplicate", "link", currLink, "duplicate of", pi.DuplicateOf)
+			return nil
+		}
+		if pi.PageMeta.NoFollow() {
+			log.Info("not following links of nofollow page", "link", currLink)
+			c.recordNoFollow(links, currLink, currDepth+1)
+			return nil
 		}
 	}
 
 	return links
 }
+
+// Records the links of a nofollow page as disallowed by the robots directives of the page, so
+// that they still show up in reports.
+func (c *Client) recordNoFollow(links []string, currLink string, nextDepth int) {
+	for _, link := range links {
+		c.RecordAttempt(link, AttemptInfo{
+			Depth:   nextDepth,
+			Parent:  currLink,
+			Outcome: OutcomeRobotsDisallowed,
+		})
+	}
+}
+
+// Removes the links that are blacklisted or out of scope from the links of the current page.
+func (c *Client) filterLinks(links []string, currLink string, nextDepth int) []string {
+	return c.removeOutOfScope(c.removeBlacklisted(links, currLink, nextDepth), currLink, nextDepth)
+}
+
+// Removes links whose host is blacklisted or not allowed, recording them as skipped so that they still show
+// up in reports.
+func (c *Client) removeBlacklisted(links []string, currLink string, nextDepth int) []string {
+	filtered := links[:0]
+	for _, link := range links {
+		parsedUrl, err := url.Parse(link)
+		if err == nil {
+			if !c.isHostAllowed(parsedUrl.Host) {
+				c.RecordAttempt(link, AttemptInfo{
+					Depth:   nextDepth,
+					Parent:  currLink,
+					Outcome: OutcomeBlacklisted,
+				})
+				continue
+			}
+		}
+		filtered = append(filtered, link)
+	}
+	return filtered
+}
+
+// Removes links that are not in the scope of the crawl, if any, recording them as skipped with
+// the reason so that they still show up in reports.
+func (c *Client) removeOutOfScope(links []string, currLink string, nextDepth int) []string {
+	if c.scope == nil {
+		return links
+	}
+	filtered := links[:0]
+	for _, link := range links {
+		if ok, reason := c.scope.Check(link, nextDepth); !ok {
+			c.RecordAttempt(link, AttemptInfo{
+				Depth:   nextDepth,
+				Parent:  currLink,
+				Outcome: OutcomeOutOfScope,
+				Scope:   reason,
+			})
+			continue
+		}
+		filtered = append(filtered, link)
+	}
+	return filtered
+}
@@ -1,5 +1,9 @@
 package gocrawler
 
+import (
+	"time"
+)
+
 // These values can be used for the users' benefit should they want to pass it
 // to another program or export it to a JSON file for convenience.
 
@@ -15,16 +19,128 @@ type NetworkInfo struct {
 	PathCount     int      `json:"path_count"`
 	VisitedPaths  []string `json:"visited_paths"`
 
-	// These values are not exported to JSON
+	// Bytes of the response bodies read from the host, as transferred and after decoding
+	CompressedBytes   int64 `json:"compressed_bytes"`
+	DecompressedBytes int64 `json:"decompressed_bytes"`
+
+	// These values are not exported to JSON, RequestCount is the number of responses received
+	// from the host, including repeated requests to the same path
+	RequestCount        int                 `json:"-"`
 	TotalResponseTimeMs int64               `json:"-"`
 	VisitedPathSet      map[string]struct{} `json:"-"`
 }
 
+// TransferInfo is how many bytes of response bodies were transferred, and how many bytes they
+// decoded to. The bodies are requested with gzip, deflate, brotli and zstd encoding.
+type TransferInfo struct {
+	ContentEncoding   string `json:"content_encoding,omitempty"`
+	CompressedBytes   int64  `json:"compressed_bytes"`
+	DecompressedBytes int64  `json:"decompressed_bytes"`
+}
+
+// TransferTotals returns the bytes of all the response bodies read during the crawl, as
+// transferred and after decoding.
+func (c *Client) TransferTotals() TransferInfo {
+	c.NetMutex.RLock()
+	defer c.NetMutex.RUnlock()
+
+	var total TransferInfo
+	for _, infos := range c.VisitedNetInfo {
+		for _, info := range infos {
+			total.CompressedBytes += info.CompressedBytes
+			total.DecompressedBytes += info.DecompressedBytes
+		}
+	}
+	return total
+}
+
 type PageInfo struct {
-	Depth  int      `json:"depth"`
-	Parent string   `json:"parent"`
-	Links  []string `json:"links"`
+	Depth     int           `json:"depth"`
+	Parent    string        `json:"parent"`
+	Links     []string      `json:"links"`
+	Redirects []RedirectHop `json:"redirects,omitempty"`
+
+	// The <a> tags of HTML pages to each of the links, keyed by link
+	Anchors map[string]LinkAnchors `json:"anchors,omitempty"`
+
+	// The body can be loaded from the crawler's ContentStore using the hash. The body of a page
+	// carried forward from the previous crawl (NotModified) is not received again, so its hash
+	// refers to the ContentStore of the previous crawl, which is only the same store if it is
+	// shared between crawls (e.g. an FSStore in the same directory). Truncated is set if the
+	// body was larger than the max body size.
+	ContentHash string `json:"content_hash,omitempty"`
+	Truncated   bool   `json:"truncated,omitempty"`
+
+	// ContentType is the media type of the response, without parameters. Charset is the detected
+	// encoding of text responses, which are transcoded to UTF-8 before links are extracted.
+	// Metadata is what the content handler of the media type extracted, if any, see
+	// ContentHandlers.
+	ContentType string            `json:"content_type,omitempty"`
+	Charset     string            `json:"charset,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+
+	// The title, description, canonical URL, robots directives, headings and social/structured
+	// metadata of HTML pages, and the X-Robots-Tag directives of other pages, only set if
+	// Config.ExtractPageMeta is set
+	PageMeta *PageMeta `json:"page_meta,omitempty"`
+
+	// The title, word count and reading time of the main text of HTML pages, only set if
+	// Config.ExtractText is set. The text itself is written to Config.Corpus, except for pages
+	// carried forward from the previous crawl
+	Article *ArticleInfo `json:"article,omitempty"`
+
+	// The records scraped from HTML pages keyed by the name of their rule set, only set if
+	// Config.ScrapeRules is set and any of its rule sets match the page, see the scrape package
+	Scraped map[string]any `json:"scraped,omitempty"`
+
+	// Bytes of the body as transferred and after decoding its Content-Encoding
+	Transfer TransferInfo `json:"transfer"`
+
+	// SimHash of the visible text of the page, see the fingerprint package. DuplicateOf is the
+	// page visited before this one that has the same or a near-identical body, if any.
+	SimHash     uint64 `json:"simhash,omitempty"`
+	DuplicateOf string `json:"duplicate_of,omitempty"`
+
+	// Used to recrawl incrementally. The validators are sent in conditional requests, CheckedAt
+	// is when the page was last fetched or confirmed to be unchanged, and ChangedAt is when its
+	// content last changed. Checks and Changes count the crawls that checked the page and found
+	// it changed. NotModified is set if the page was carried forward from the previous crawl.
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	CheckedAt    time.Time `json:"checked_at"`
+	ChangedAt    time.Time `json:"changed_at"`
+	Checks       int       `json:"checks,omitempty"`
+	Changes      int       `json:"changes,omitempty"`
+	NotModified  bool      `json:"not_modified,omitempty"`
+}
+
+// LinkAnchors are the <a> tags of a page that link to the same URL, and their distinct
+// anchor texts.
+type LinkAnchors struct {
+	Count int      `json:"count"`
+	Texts []string `json:"texts,omitempty"`
+}
+
+// RedirectHop is a single redirect that was followed to reach a page. Location is the
+// absolute URL that the Location header resolved to.
+type RedirectHop struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code"`
+	Location   string `json:"location"`
+}
 
-	// These values are not exported to JSON
-	Content []byte `json:"-"`
+// AttemptInfo is the outcome of an attempt to visit a URL, whether it succeeded or not.
+// FinalURL is only set if the URL was redirected, and Matcher is the name of the
+// Matcher that rejected the response, if any.
+type AttemptInfo struct {
+	Depth      int     `json:"depth"`
+	Parent     string  `json:"parent"`
+	Outcome    Outcome `json:"outcome"`
+	StatusCode int     `json:"status_code,omitempty"`
+	ErrorClass string  `json:"error_class,omitempty"`
+	Error      string  `json:"error,omitempty"`
+	Matcher    string  `json:"matcher,omitempty"`
+	FinalURL   string  `json:"final_url,omitempty"`
+	Trap       Trap    `json:"trap,omitempty"`
+	Scope      string  `json:"scope,omitempty"`
 }
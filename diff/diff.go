@@ -0,0 +1,221 @@
+// Package diff compares two crawls of the same site, loaded from the reports exported by the
+// examples or from an SQLite export, to find the pages, links, statuses and hosts that changed.
+package diff
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Diff between an old and a new crawl. Content, links and statuses are only compared for pages
+// that are in both crawls, as every link of an added or removed page is new or gone too.
+type Diff struct {
+	AddedPages     []string       `json:"added_pages"`
+	RemovedPages   []string       `json:"removed_pages"`
+	ChangedContent []string       `json:"changed_content"`
+	AddedLinks     []Link         `json:"added_links"`
+	RemovedLinks   []Link         `json:"removed_links"`
+	StatusChanges  []StatusChange `json:"status_changes"`
+	HostChanges    []HostChange   `json:"host_changes"`
+}
+
+type Link struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+type StatusChange struct {
+	URL string `json:"url"`
+	Old Status `json:"old"`
+	New Status `json:"new"`
+}
+
+// HostChange is a host, visited in both crawls, whose IPs or AS numbers changed.
+type HostChange struct {
+	Host        string   `json:"host"`
+	AddedIPs    []string `json:"added_ips"`
+	RemovedIPs  []string `json:"removed_ips"`
+	AddedASNs   []string `json:"added_asns"`
+	RemovedASNs []string `json:"removed_asns"`
+}
+
+// Compare returns the changes from the previous to the current crawl, sorted by URL.
+func Compare(prev, curr *Snapshot) Diff {
+	d := Diff{
+		AddedPages:     []string{},
+		RemovedPages:   []string{},
+		ChangedContent: []string{},
+		AddedLinks:     []Link{},
+		RemovedLinks:   []Link{},
+		StatusChanges:  []StatusChange{},
+		HostChanges:    []HostChange{},
+	}
+
+	for _, link := range sortedKeys(curr.Pages) {
+		if _, ok := prev.Pages[link]; !ok {
+			d.AddedPages = append(d.AddedPages, link)
+		}
+	}
+	for _, link := range sortedKeys(prev.Pages) {
+		oldPage := prev.Pages[link]
+		newPage, ok := curr.Pages[link]
+		if !ok {
+			d.RemovedPages = append(d.RemovedPages, link)
+			continue
+		}
+
+		// bodies are not hashed if they could not be stored
+		if oldPage.ContentHash != "" && newPage.ContentHash != "" && oldPage.ContentHash != newPage.ContentHash {
+			d.ChangedContent = append(d.ChangedContent, link)
+		}
+
+		added, removed := setDiff(oldPage.Links, newPage.Links)
+		for _, target := range added {
+			d.AddedLinks = append(d.AddedLinks, Link{Source: link, Target: target})
+		}
+		for _, target := range removed {
+			d.RemovedLinks = append(d.RemovedLinks, Link{Source: link, Target: target})
+		}
+	}
+
+	for _, link := range sortedKeys(prev.Statuses) {
+		oldStatus := prev.Statuses[link]
+		newStatus, ok := curr.Statuses[link]
+		if !ok || oldStatus.equivalent(newStatus) {
+			continue
+		}
+		d.StatusChanges = append(d.StatusChanges, StatusChange{URL: link, Old: oldStatus, New: newStatus})
+	}
+
+	for _, host := range sortedKeys(prev.Hosts) {
+		newIPs, ok := curr.Hosts[host]
+		if !ok {
+			continue
+		}
+		var oldAddrs, newAddrs, oldASNs, newASNs []string
+		for _, ip := range prev.Hosts[host] {
+			oldAddrs = append(oldAddrs, ip.IP)
+			oldASNs = append(oldASNs, ip.ASNumber)
+		}
+		for _, ip := range newIPs {
+			newAddrs = append(newAddrs, ip.IP)
+			newASNs = append(newASNs, ip.ASNumber)
+		}
+
+		hc := HostChange{Host: host}
+		hc.AddedIPs, hc.RemovedIPs = setDiff(oldAddrs, newAddrs)
+		hc.AddedASNs, hc.RemovedASNs = setDiff(oldASNs, newASNs)
+		if len(hc.AddedIPs)+len(hc.RemovedIPs)+len(hc.AddedASNs)+len(hc.RemovedASNs) > 0 {
+			d.HostChanges = append(d.HostChanges, hc)
+		}
+	}
+
+	return d
+}
+
+// Empty reports whether nothing changed between the crawls.
+func (d *Diff) Empty() bool {
+	return len(d.AddedPages)+len(d.RemovedPages)+len(d.ChangedContent)+len(d.AddedLinks)+
+		len(d.RemovedLinks)+len(d.StatusChanges)+len(d.HostChanges) == 0
+}
+
+// WriteText writes a readable summary of the diff, with a line per change grouped by the kind
+// of change. "+" marks something that was added, "-" something that was removed, and "~"
+// something that changed.
+func (d *Diff) WriteText(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d pages added, %d removed, %d with changed content\n", len(d.AddedPages), len(d.RemovedPages), len(d.ChangedContent))
+	fmt.Fprintf(&b, "%d links added, %d removed\n", len(d.AddedLinks), len(d.RemovedLinks))
+	fmt.Fprintf(&b, "%d status changes, %d host changes\n", len(d.StatusChanges), len(d.HostChanges))
+
+	section := func(title string, n int) {
+		if n > 0 {
+			fmt.Fprintf(&b, "\n%s:\n", title)
+		}
+	}
+
+	section("Added pages", len(d.AddedPages))
+	for _, link := range d.AddedPages {
+		fmt.Fprintf(&b, "  + %s\n", link)
+	}
+	section("Removed pages", len(d.RemovedPages))
+	for _, link := range d.RemovedPages {
+		fmt.Fprintf(&b, "  - %s\n", link)
+	}
+	section("Changed content", len(d.ChangedContent))
+	for _, link := range d.ChangedContent {
+		fmt.Fprintf(&b, "  ~ %s\n", link)
+	}
+	section("Added links", len(d.AddedLinks))
+	for _, l := range d.AddedLinks {
+		fmt.Fprintf(&b, "  + %s -> %s\n", l.Source, l.Target)
+	}
+	section("Removed links", len(d.RemovedLinks))
+	for _, l := range d.RemovedLinks {
+		fmt.Fprintf(&b, "  - %s -> %s\n", l.Source, l.Target)
+	}
+	section("Status changes", len(d.StatusChanges))
+	for _, sc := range d.StatusChanges {
+		fmt.Fprintf(&b, "  ~ %s: %s -> %s\n", sc.URL, sc.Old, sc.New)
+	}
+	section("Host changes", len(d.HostChanges))
+	for _, hc := range d.HostChanges {
+		fmt.Fprintf(&b, "  ~ %s\n", hc.Host)
+		for _, ip := range hc.AddedIPs {
+			fmt.Fprintf(&b, "      + IP %s\n", ip)
+		}
+		for _, ip := range hc.RemovedIPs {
+			fmt.Fprintf(&b, "      - IP %s\n", ip)
+		}
+		for _, asn := range hc.AddedASNs {
+			fmt.Fprintf(&b, "      + ASN %s\n", asn)
+		}
+		for _, asn := range hc.RemovedASNs {
+			fmt.Fprintf(&b, "      - ASN %s\n", asn)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Returns the unique, sorted values that are only in curr (added) and only in prev (removed).
+// Empty values, such as the AS number of an IP that could not be looked up, are ignored.
+func setDiff(prev, curr []string) ([]string, []string) {
+	toSet := func(vs []string) map[string]struct{} {
+		set := make(map[string]struct{}, len(vs))
+		for _, v := range vs {
+			if v != "" {
+				set[v] = struct{}{}
+			}
+		}
+		return set
+	}
+	prevSet, currSet := toSet(prev), toSet(curr)
+
+	added, removed := []string{}, []string{}
+	for v := range currSet {
+		if _, ok := prevSet[v]; !ok {
+			added = append(added, v)
+		}
+	}
+	for v := range prevSet {
+		if _, ok := currSet[v]; !ok {
+			removed = append(removed, v)
+		}
+	}
+	slices.Sort(added)
+	slices.Sort(removed)
+	return added, removed
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,113 @@
+package diff_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yusufaine/gocrawler"
+	"github.com/yusufaine/gocrawler/diff"
+	"github.com/yusufaine/gocrawler/export"
+)
+
+func newClient(pages map[string]gocrawler.PageInfo, attempts map[string]gocrawler.AttemptInfo, ips []gocrawler.IPInfo) *gocrawler.Client {
+	return &gocrawler.Client{
+		VisitedPageInfo: pages,
+		Attempts:        attempts,
+		VisitedNetInfo: map[string][]gocrawler.NetworkInfo{
+			"a.com": {{RemoteIPInfo: ips, VisitedPathSet: map[string]struct{}{"/": {}}}},
+		},
+	}
+}
+
+func TestCompare(t *testing.T) {
+	prev := newClient(
+		map[string]gocrawler.PageInfo{
+			"https://a.com/":    {ContentHash: "1", Links: []string{"https://a.com/b", "https://a.com/c"}},
+			"https://a.com/b":   {ContentHash: "2"},
+			"https://a.com/c":   {ContentHash: "6"},
+			"https://a.com/old": {ContentHash: "3"},
+		},
+		map[string]gocrawler.AttemptInfo{
+			"https://a.com/":    {Outcome: gocrawler.OutcomeOK, StatusCode: 200},
+			"https://a.com/b":   {Outcome: gocrawler.OutcomeOK, StatusCode: 200},
+			"https://a.com/c":   {Outcome: gocrawler.OutcomeOK, StatusCode: 200},
+			"https://a.com/old": {Outcome: gocrawler.OutcomeOK, StatusCode: 200},
+		},
+		[]gocrawler.IPInfo{{IP: "1.1.1.1", ASNumber: "AS1"}},
+	)
+	curr := newClient(
+		map[string]gocrawler.PageInfo{
+			"https://a.com/":    {ContentHash: "1", Links: []string{"https://a.com/b", "https://a.com/c", "https://a.com/new"}},
+			"https://a.com/b":   {ContentHash: "4"},
+			"https://a.com/new": {ContentHash: "5"},
+		},
+		map[string]gocrawler.AttemptInfo{
+			"https://a.com/":    {Outcome: gocrawler.OutcomeOK, StatusCode: 200},
+			"https://a.com/b":   {Outcome: gocrawler.OutcomeOK, StatusCode: 200},
+			"https://a.com/c":   {Outcome: gocrawler.OutcomeHTTPError, StatusCode: 404},
+			"https://a.com/new": {Outcome: gocrawler.OutcomeOK, StatusCode: 200},
+		},
+		[]gocrawler.IPInfo{{IP: "1.1.1.1", ASNumber: "AS1"}, {IP: "2.2.2.2", ASNumber: "AS2"}},
+	)
+
+	exp := diff.Diff{
+		AddedPages:     []string{"https://a.com/new"},
+		RemovedPages:   []string{"https://a.com/c", "https://a.com/old"},
+		ChangedContent: []string{"https://a.com/b"},
+		AddedLinks:     []diff.Link{{Source: "https://a.com/", Target: "https://a.com/new"}},
+		RemovedLinks:   []diff.Link{},
+		StatusChanges: []diff.StatusChange{{
+			URL: "https://a.com/c",
+			Old: diff.Status{Outcome: gocrawler.OutcomeOK, StatusCode: 200},
+			New: diff.Status{Outcome: gocrawler.OutcomeHTTPError, StatusCode: 404},
+		}},
+		HostChanges: []diff.HostChange{{
+			Host:        "a.com",
+			AddedIPs:    []string{"2.2.2.2"},
+			RemovedIPs:  []string{},
+			AddedASNs:   []string{"AS2"},
+			RemovedASNs: []string{},
+		}},
+	}
+	d := diff.Compare(diff.FromClient(prev), diff.FromClient(curr))
+	if !reflect.DeepEqual(d, exp) {
+		t.Errorf("Expected %+v, got %+v", exp, d)
+	}
+
+	// the crawls should compare the same after a round trip through an SQLite export
+	path := filepath.Join(t.TempDir(), "crawls.db")
+	for _, cr := range []*gocrawler.Client{prev, curr} {
+		if _, err := export.WriteSQLite(path, export.Crawl{StartedAt: time.Now()}, export.Tables(cr)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	prevSnap, err := diff.Load(path, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	currSnap, err := diff.Load(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := diff.Compare(prevSnap, currSnap); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %+v from SQLite, got %+v", exp, got)
+	}
+
+	var buf bytes.Buffer
+	if err := d.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		"1 pages added, 2 removed, 1 with changed content",
+		"  ~ https://a.com/c: ok (200) -> http_error (404)",
+		"      + IP 2.2.2.2",
+	} {
+		if !strings.Contains(buf.String(), line+"\n") {
+			t.Errorf("Expected summary to contain %q, got:\n%s", line, buf.String())
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package diff
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/yusufaine/gocrawler"
+
+	// pure-Go SQLite driver so that the examples can be built without cgo
+	_ "modernc.org/sqlite"
+)
+
+// Status of a URL that the crawler fetched, or failed to fetch.
+type Status struct {
+	Outcome    gocrawler.Outcome `json:"outcome"`
+	StatusCode int               `json:"status_code,omitempty"`
+}
+
+func (s Status) String() string {
+	if s.StatusCode == 0 {
+		return string(s.Outcome)
+	}
+	return fmt.Sprintf("%s (%d)", s.Outcome, s.StatusCode)
+}
+
+// A page that was revalidated with 304 Not Modified by an incremental recrawl has the same
+// status as when it was fetched.
+func (s Status) equivalent(o Status) bool {
+	if s.Outcome == gocrawler.OutcomeOK && o.Outcome == gocrawler.OutcomeOK &&
+		(s.StatusCode == http.StatusNotModified || o.StatusCode == http.StatusNotModified) {
+		return true
+	}
+	return s == o
+}
+
+// Snapshot is the part of a crawl that is compared. Only the content hash and links of pages
+// are used, statuses are keyed by the URL that was finally fetched after redirects.
+type Snapshot struct {
+	Pages    map[string]gocrawler.PageInfo
+	Statuses map[string]Status
+	Hosts    map[string][]gocrawler.IPInfo
+}
+
+// FromClient takes a snapshot of the crawl done by the crawler.
+func FromClient(cr *gocrawler.Client) *Snapshot {
+	hosts := make(map[string][]gocrawler.IPInfo, len(cr.VisitedNetInfo))
+	for host, nis := range cr.VisitedNetInfo {
+		for _, ni := range nis {
+			hosts[host] = append(hosts[host], ni.RemoteIPInfo...)
+		}
+	}
+	return &Snapshot{
+		Pages:    cr.VisitedPageInfo,
+		Statuses: statuses(cr.Attempts),
+		Hosts:    hosts,
+	}
+}
+
+// Only outcomes where the URL was fetched, or failed to be, have a status.
+func statuses(attempts map[string]gocrawler.AttemptInfo) map[string]Status {
+	s := make(map[string]Status, len(attempts))
+	for link, ai := range attempts {
+		if ai.Outcome != gocrawler.OutcomeOK && !ai.Outcome.IsError() {
+			continue
+		}
+		if ai.FinalURL != "" {
+			link = ai.FinalURL
+		}
+		s[link] = Status{Outcome: ai.Outcome, StatusCode: ai.StatusCode}
+	}
+	return s
+}
+
+// Load loads a snapshot from an SQLite database written by the export package, see LoadSQLite,
+// or from a report exported by the examples, see LoadReport.
+func Load(path string, crawlID int64) (*Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, len(sqliteHeader))
+	_, err = io.ReadFull(f, header)
+	f.Close()
+	if err == nil && bytes.Equal(header, sqliteHeader) {
+		return LoadSQLite(path, crawlID)
+	}
+	return LoadReport(path)
+}
+
+var sqliteHeader = []byte("SQLite format 3\x00")
+
+// LoadReport loads a snapshot from a report exported by the examples (e.g. sitemapper), which
+// contains the "page_info", "network_info" and "attempts" of the crawl.
+func LoadReport(path string) (*Snapshot, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var report struct {
+		Pages    map[string]gocrawler.PageInfo      `json:"page_info"`
+		NetInfo  map[string][]gocrawler.NetworkInfo `json:"network_info"`
+		Attempts map[string]gocrawler.AttemptInfo   `json:"attempts"`
+	}
+	if err := json.Unmarshal(b, &report); err != nil {
+		return nil, err
+	}
+	if report.Pages == nil {
+		return nil, errors.New("report does not contain any page_info")
+	}
+
+	hosts := make(map[string][]gocrawler.IPInfo, len(report.NetInfo))
+	for host, nis := range report.NetInfo {
+		for _, ni := range nis {
+			hosts[host] = append(hosts[host], ni.RemoteIPInfo...)
+		}
+	}
+	return &Snapshot{
+		Pages:    report.Pages,
+		Statuses: statuses(report.Attempts),
+		Hosts:    hosts,
+	}, nil
+}
+
+// LoadSQLite loads the crawl with the ID from an SQLite database written by the export package,
+// or the latest crawl if the ID is 0.
+func LoadSQLite(path string, crawlID int64) (*Snapshot, error) {
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	if crawlID == 0 {
+		var latest sql.NullInt64
+		if err := db.QueryRow("SELECT MAX(id) FROM crawls").Scan(&latest); err != nil {
+			return nil, err
+		}
+		if !latest.Valid {
+			return nil, errors.New("database does not contain any crawls")
+		}
+		crawlID = latest.Int64
+	}
+
+	s := &Snapshot{
+		Pages:    make(map[string]gocrawler.PageInfo),
+		Statuses: make(map[string]Status),
+		Hosts:    make(map[string][]gocrawler.IPInfo),
+	}
+	err = query(db, "SELECT url, content_hash, status_code FROM pages WHERE crawl_id = ?", crawlID, func(rows *sql.Rows) error {
+		var (
+			link, hash string
+			statusCode int
+		)
+		if err := rows.Scan(&link, &hash, &statusCode); err != nil {
+			return err
+		}
+		s.Pages[link] = gocrawler.PageInfo{ContentHash: hash}
+		s.Statuses[link] = Status{Outcome: gocrawler.OutcomeOK, StatusCode: statusCode}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = query(db, "SELECT source_url, target_url FROM links WHERE crawl_id = ?", crawlID, func(rows *sql.Rows) error {
+		var source, target string
+		if err := rows.Scan(&source, &target); err != nil {
+			return err
+		}
+		pi := s.Pages[source]
+		pi.Links = append(pi.Links, target)
+		s.Pages[source] = pi
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = query(db, "SELECT url, final_url, outcome, status_code FROM errors WHERE crawl_id = ?", crawlID, func(rows *sql.Rows) error {
+		var (
+			link, finalURL, outcome string
+			statusCode              int
+		)
+		if err := rows.Scan(&link, &finalURL, &outcome, &statusCode); err != nil {
+			return err
+		}
+		if finalURL != "" {
+			link = finalURL
+		}
+		s.Statuses[link] = Status{Outcome: gocrawler.Outcome(outcome), StatusCode: statusCode}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = query(db, "SELECT host, ip, location, as_number FROM ips WHERE crawl_id = ?", crawlID, func(rows *sql.Rows) error {
+		var (
+			host string
+			ip   gocrawler.IPInfo
+		)
+		if err := rows.Scan(&host, &ip.IP, &ip.Location, &ip.ASNumber); err != nil {
+			return err
+		}
+		s.Hosts[host] = append(s.Hosts[host], ip)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(s.Pages) == 0 {
+		return nil, fmt.Errorf("crawl %d does not contain any pages", crawlID)
+	}
+	return s, nil
+}
+
+func query(db *sql.DB, q string, crawlID int64, scan func(*sql.Rows) error) error {
+	rows, err := db.Query(q, crawlID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
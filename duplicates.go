@@ -0,0 +1,61 @@
+package gocrawler
+
+import (
+	"sync"
+
+	"github.com/yusufaine/gocrawler/fingerprint"
+)
+
+// Indexes the pages visited so far by their content hash and SimHash, so that the page that a
+// new page duplicates can be found without comparing it with every visited page.
+type dupState struct {
+	mu      sync.Mutex
+	hashes  map[string]string  // content hash to the first page with it
+	simhash *fingerprint.Index // nil if only exact duplicates are detected
+}
+
+// Returns nil if duplicates are not detected, in which case PageInfo.DuplicateOf is never set.
+func newDupState(detect bool, nearDupDistance int) *dupState {
+	if !detect {
+		return nil
+	}
+	ds := &dupState{hashes: make(map[string]string)}
+	if nearDupDistance >= 0 {
+		ds.simhash = fingerprint.NewIndex(nearDupDistance)
+	}
+	return ds
+}
+
+// Returns the page visited before this one with the same content hash, or the closest SimHash
+// within the near-duplicate distance unless it is < 0, and adds the page to the index. Ties are
+// broken by the URL so that the result does not depend on the order of the pages. Pages without
+// text are only compared by their content hash.
+func (c *Client) findDuplicate(link, contentHash string, simhash uint64) string {
+	ds := c.duplicates
+	if ds == nil {
+		return ""
+	}
+
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	if contentHash != "" {
+		if dup, ok := ds.hashes[contentHash]; ok {
+			// the same link can be fetched twice if it was found by two pages at once
+			if dup == link {
+				return ""
+			}
+			return dup
+		}
+		ds.hashes[contentHash] = link
+	}
+	if ds.simhash == nil || simhash == 0 {
+		return ""
+	}
+
+	dup, _, ok := ds.simhash.Nearest(simhash)
+	ds.simhash.Add(link, simhash)
+	if !ok || dup == link {
+		return ""
+	}
+	return dup
+}
@@ -0,0 +1,39 @@
+package gocrawler
+
+import "testing"
+
+func TestFindDuplicate(t *testing.T) {
+	c := &Client{duplicates: newDupState(true, 3)}
+	steps := []struct {
+		link, hash string
+		simhash    uint64
+		exp        string
+	}{
+		{"https://a.com/", "h1", 0b1111_0000, ""},
+		{"https://a.com/", "h1", 0b1111_0000, ""}, // fetched twice, not a duplicate of itself
+		{"https://a.com/copy", "h1", 0b1111_0000, "https://a.com/"},
+		{"https://a.com/near", "h2", 0b1111_0111, "https://a.com/"},
+		{"https://a.com/far", "h3", 0b0000_1111, ""},
+		{"https://a.com/empty", "h4", 0, ""},
+		{"https://a.com/empty2", "h5", 0, ""}, // pages without text only match by hash
+	}
+	for _, s := range steps {
+		if got := c.findDuplicate(s.link, s.hash, s.simhash); got != s.exp {
+			t.Errorf("Expected %s to be a duplicate of %q, got %q", s.link, s.exp, got)
+		}
+	}
+
+	// only exact duplicates
+	c = &Client{duplicates: newDupState(true, -1)}
+	c.findDuplicate("https://a.com/", "h1", 0b1111_0000)
+	if got := c.findDuplicate("https://a.com/near", "h2", 0b1111_0111); got != "" {
+		t.Errorf("Expected no near-duplicates with a negative distance, got %q", got)
+	}
+
+	// disabled
+	c = &Client{duplicates: newDupState(false, 3)}
+	c.findDuplicate("https://a.com/", "h1", 0b1111_0000)
+	if got := c.findDuplicate("https://a.com/copy", "h1", 0b1111_0000); got != "" {
+		t.Errorf("Expected no duplicates when detection is disabled, got %q", got)
+	}
+}
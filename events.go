@@ -0,0 +1,63 @@
+package gocrawler
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+type EventType string
+
+const (
+	EventPage  EventType = "page"  // a page was fetched and its links extracted
+	EventHost  EventType = "host"  // a new host was visited
+	EventError EventType = "error" // a URL could not be fetched or returned an error status
+)
+
+// Event is a single line of the NDJSON event stream, only the fields relevant to the type
+// of event are set.
+type Event struct {
+	Type         EventType    `json:"type"`
+	Time         time.Time    `json:"time"`
+	URL          string       `json:"url,omitempty"`
+	Page         *PageInfo    `json:"page,omitempty"`
+	Host         string       `json:"host,omitempty"`
+	RemoteIPInfo []IPInfo     `json:"remote_ip_info,omitempty"`
+	Attempt      *AttemptInfo `json:"attempt,omitempty"`
+}
+
+// Writes values as newline-delimited JSON, one line at a time so that the lines written so far
+// are readable even if the process is killed.
+type lineWriter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func newLineWriter(w io.Writer) *lineWriter {
+	if w == nil {
+		return nil
+	}
+	return &lineWriter{enc: json.NewEncoder(w)}
+}
+
+func (w *lineWriter) write(v any) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.enc.Encode(v)
+}
+
+// Writes the event to the event stream, if any. This blocks until the event is written, so it
+// must not be called while holding any of the crawler's mutexes, otherwise a slow reader
+// (e.g. a pipe on stdout) would stall every goroutine of the crawl.
+func (c *Client) emit(ev Event) {
+	if c.events == nil {
+		return
+	}
+	ev.Time = time.Now()
+	if err := c.events.write(ev); err != nil {
+		log.Error("unable to write event", "type", ev.Type, "error", err)
+	}
+}
@@ -0,0 +1,89 @@
+package gocrawler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventStream(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<a href="/b">B</a><a href="/missing">Missing</a>`))
+	})
+	mux.HandleFunc("/b", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<p>B</p>`))
+	})
+	mux.HandleFunc("/missing", http.NotFound)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	var events bytes.Buffer
+	c := New(context.Background(), &Config{
+		Events:     &events,
+		MaxDepth:   2,
+		MaxRetries: 1,
+		MaxRPS:     100,
+		Timeout:    5 * time.Second,
+		ProxyURL:   &url.URL{},
+	}, nil, DefaultLinkExtractor)
+	c.Crawl(context.Background(), 0, srv.URL+"/", "")
+
+	pages := make(map[string]bool)
+	var (
+		hosts  []string
+		errors []Event
+	)
+	sc := bufio.NewScanner(&events)
+	for sc.Scan() {
+		line := sc.Bytes()
+		if !json.Valid(line) {
+			t.Fatalf("Expected every line to be valid JSON, got %s", line)
+		}
+		var ev Event
+		if err := json.Unmarshal(line, &ev); err != nil {
+			t.Fatal(err)
+		}
+		if ev.Time.IsZero() {
+			t.Errorf("Expected the time of the %s event to be set", ev.Type)
+		}
+		switch ev.Type {
+		case EventPage:
+			if ev.Page == nil {
+				t.Errorf("Expected the page event of %s to have the page", ev.URL)
+			}
+			pages[ev.URL] = true
+		case EventHost:
+			hosts = append(hosts, ev.Host)
+		case EventError:
+			errors = append(errors, ev)
+		default:
+			t.Errorf("Expected a page, host or error event, got %s", ev.Type)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, link := range []string{srv.URL + "/", srv.URL + "/b"} {
+		if !pages[link] {
+			t.Errorf("Expected a page event of %s, got %v", link, pages)
+		}
+	}
+	if host := strings.TrimPrefix(srv.URL, "http://"); len(hosts) != 1 || hosts[0] != host {
+		t.Errorf("Expected a single host event of %s, got %v", host, hosts)
+	}
+	if len(errors) != 1 || errors[0].URL != srv.URL+"/missing" || errors[0].Attempt == nil ||
+		errors[0].Attempt.Outcome != OutcomeHTTPError || errors[0].Attempt.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected an error event of %s with status 404, got %+v", srv.URL+"/missing", errors)
+	}
+}
@@ -0,0 +1,69 @@
+package crawldiff
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/yusufaine/gocrawler/internal/logger"
+)
+
+type Config struct {
+	NewCrawl    int64
+	NewPath     string
+	OldCrawl    int64
+	OldPath     string
+	ReportPath  string
+	SummaryPath string
+}
+
+// SetupConfig parses the paths of the two crawls to compare and where to write the diff to.
+func SetupConfig() *Config {
+	var (
+		c       Config
+		verbose bool
+	)
+
+	// YYYY-MM-DD_HH-MM
+	defaultReport := fmt.Sprintf("crawldiff_%s.json", time.Now().Format("2006-01-02_15-04"))
+
+	flag.StringVar(&c.OldPath, "old", "", "Report or SQLite export of the previous crawl, required")
+	flag.StringVar(&c.NewPath, "new", "", "Report or SQLite export of the current crawl, required")
+	flag.Int64Var(&c.OldCrawl, "old-crawl", 0, "ID of the previous crawl if --old is an SQLite export, 0 for the latest crawl")
+	flag.Int64Var(&c.NewCrawl, "new-crawl", 0, "ID of the current crawl if --new is an SQLite export, 0 for the latest crawl")
+	flag.StringVar(&c.ReportPath, "report", defaultReport, "Path to export the diff to as JSON, '-' for stdout")
+	flag.StringVar(&c.SummaryPath, "summary", "-", "Path to write a readable summary of the diff to, '-' for stdout, disabled if empty")
+	flag.BoolVar(&verbose, "verbose", false, "Verbose logging, includes short caller info")
+	flag.Parse()
+	logger.Setup(verbose)
+
+	c.mustValidate()
+
+	return &c
+}
+
+func (c *Config) mustValidate() {
+	if c.OldPath == "" {
+		panic("--old is required!")
+	}
+	if c.NewPath == "" {
+		panic("--new is required!")
+	}
+	if c.OldCrawl < 0 || c.NewCrawl < 0 {
+		panic("--old-crawl and --new-crawl must be >= 0")
+	}
+	if c.OldPath == c.NewPath && c.OldCrawl == c.NewCrawl {
+		panic("--old and --new are the same crawl, set --old-crawl to compare crawls in the same database")
+	}
+}
+
+func (c *Config) PrintConfig() {
+	log.Info("Running with config: ")
+	log.Info(" ", "old", c.OldPath)
+	log.Info(" ", "old crawl", c.OldCrawl)
+	log.Info(" ", "new", c.NewPath)
+	log.Info(" ", "new crawl", c.NewCrawl)
+	log.Info(" ", "report", c.ReportPath)
+	log.Info(" ", "summary", c.SummaryPath)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"github.com/charmbracelet/log"
+	"github.com/yusufaine/gocrawler/diff"
+	"github.com/yusufaine/gocrawler/example/crawldiff/internal/crawldiff"
+	"github.com/yusufaine/gocrawler/example/internal/filewriter"
+)
+
+func main() {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Fatal(r)
+		}
+	}()
+
+	config := crawldiff.SetupConfig()
+	config.PrintConfig()
+
+	prev, err := diff.Load(config.OldPath, config.OldCrawl)
+	if err != nil {
+		log.Fatal("unable to load previous crawl", "file", config.OldPath, "error", err)
+	}
+	curr, err := diff.Load(config.NewPath, config.NewCrawl)
+	if err != nil {
+		log.Fatal("unable to load current crawl", "file", config.NewPath, "error", err)
+	}
+
+	d := diff.Compare(prev, curr)
+	if err := filewriter.ToJSON(d, config.ReportPath); err != nil {
+		log.Error("unable to write to file", "file", config.ReportPath, "error", err)
+	} else {
+		log.Info("exported crawl diff", "file", config.ReportPath)
+	}
+
+	if config.SummaryPath != "" {
+		f, err := filewriter.Create(config.SummaryPath)
+		if err != nil {
+			log.Fatal("unable to create summary", "file", config.SummaryPath, "error", err)
+		}
+		defer f.Close()
+		if err := d.WriteText(f); err != nil {
+			log.Error("unable to write summary", "file", config.SummaryPath, "error", err)
+		}
+	}
+}
@@ -6,7 +6,9 @@ import (
 
 	"github.com/charmbracelet/log"
 	"github.com/yusufaine/gocrawler"
+	"github.com/yusufaine/gocrawler/analysis"
 	"github.com/yusufaine/gocrawler/example/internal/filewriter"
+	"github.com/yusufaine/gocrawler/fingerprint"
 )
 
 type ReportFormat struct {
@@ -16,28 +18,42 @@ type ReportFormat struct {
 	MaxRPS    float64  `json:"max_rps"`
 	CrawlTime string   `json:"crawl_time"`
 
+	Transfer        gocrawler.TransferInfo             `json:"transfer"`
 	VisitedNetInfo  map[string][]gocrawler.NetworkInfo `json:"network_info"`
 	VisitedPageResp map[string]gocrawler.PageInfo      `json:"page_info"`
+	RedirectChains  map[string][]gocrawler.RedirectHop `json:"redirect_chains"`
+	Attempts        map[string]gocrawler.AttemptInfo   `json:"attempts"`
+	Analysis        analysis.Result                    `json:"analysis"`
+	DuplicateGroups [][]string                         `json:"duplicate_clusters"`
+	Traps           map[string]gocrawler.AttemptInfo   `json:"traps"`
 }
 
-// Generates a report in JSON format from the crawler client and config. The report contains
-// the initial crawler info, the network info for each host visited, and the page info for each
-// page visited such as all the links found in the page.
+// Generates a report in JSON format from the crawler client and config. The report contains the
+// initial crawler info, the bytes of the bodies transferred and decoded, the network info for each
+// host visited, the redirect chains followed, the page info for each page visited such as all the
+// links found in the page, the outcome of every URL that the crawler attempted to visit, the links
+// skipped as crawler traps, the link-graph analysis of the pages, and the clusters of pages that
+// are duplicates or near-duplicates of each other.
 func Generate(config *Config, cr *gocrawler.Client, elapsed time.Duration) {
-	bls := make([]string, 0, len(cr.HostBlacklist))
-	for k := range cr.HostBlacklist {
-		bls = append(bls, k)
+	dupDistance := config.NearDupDistance
+	if dupDistance == 0 {
+		dupDistance = fingerprint.DefaultDistance
 	}
-	slices.Sort(bls)
 
 	report := ReportFormat{
 		Seeds:           config.SeedURLs,
 		Depth:           config.MaxDepth,
-		Blacklist:       bls,
+		Blacklist:       cr.HostBlacklist.Strings(),
 		MaxRPS:          config.MaxRPS,
 		CrawlTime:       elapsed.String(),
+		Transfer:        cr.TransferTotals(),
 		VisitedNetInfo:  cr.VisitedNetInfo,
 		VisitedPageResp: cr.VisitedPageInfo,
+		RedirectChains:  cr.VisitedRedirects,
+		Attempts:        cr.Attempts,
+		Analysis:        analysis.FromClient(cr).Analyse(),
+		DuplicateGroups: analysis.DuplicateClusters(cr.VisitedPageInfo, dupDistance),
+		Traps:           cr.Traps(),
 	}
 	for k, v := range report.VisitedNetInfo {
 		for i, v1 := range v {
@@ -49,11 +65,7 @@ func Generate(config *Config, cr *gocrawler.Client, elapsed time.Duration) {
 			slices.Sort(v1.VisitedPaths)
 			report.VisitedNetInfo[k][i] = v1
 
-			visitedCount := int64(len(v1.VisitedPaths))
-			if visitedCount == 0 {
-				visitedCount = 1
-			}
-			v1.AvgResponseMs = v1.TotalResponseTimeMs / visitedCount
+			v1.AvgResponseMs = v1.TotalResponseTimeMs / int64(max(v1.RequestCount, 1))
 			report.VisitedNetInfo[k][i] = v1
 		}
 	}
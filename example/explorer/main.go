@@ -33,14 +33,31 @@ func main() {
 	time.Sleep(3 * time.Second)
 	start := time.Now()
 
+	// the WARC files and HAR log are closed after the report is generated
+	closeOutputs := config.Outputs.Setup("explorer", &config.Config)
+	defer closeOutputs()
+
+	// mixed content crawls accept every response that a content handler can extract links from
+	var rm gocrawler.Matcher = gocrawler.ResponseMatcher(gocrawler.IsHtmlContent)
+	if config.ContentHandlers != nil {
+		rm = gocrawler.And(gocrawler.ResponseMatcher(gocrawler.IsOkResponse), config.ContentHandlers.Matcher())
+	}
+
 	cr := gocrawler.New(ctx,
 		&config.Config,
-		[]gocrawler.ResponseMatcher{gocrawler.IsHtmlContent},
-		explorer.ExplorerLinkExtractor,
+		[]gocrawler.Matcher{rm},
+		gocrawler.DefaultLinkExtractor,
 	)
+	// pages that the sitemap says are unchanged are carried forward without requesting them
+	if config.Previous != nil {
+		cr.LoadSitemaps(config.SeedURLs)
+	}
+
 	defer func() {
 		log.Info("generating explorer report", "file", config.ReportPath)
 		explorer.Generate(config, cr, time.Since(start))
+
+		config.Outputs.Write(cr, &config.Config, start)
 	}()
 
 	go func() {
@@ -1,9 +1,13 @@
 package filewriter
 
 import (
+	"encoding/csv"
 	"encoding/json"
+	"io"
 	"os"
 	"path/filepath"
+
+	"github.com/yusufaine/gocrawler/graph"
 )
 
 func ToJSON(v any, filename string) error {
@@ -15,20 +19,71 @@ func ToJSON(v any, filename string) error {
 	// add new line
 	d = append(d, "\n"...)
 
-	// create folder if not exists
-	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+	f, err := Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(d); err != nil {
 		return err
 	}
 
-	f, err := os.Create(filename)
+	return nil
+}
+
+// ToCSV writes the header followed by the rows to the file.
+func ToCSV(header []string, rows [][]string, filename string) error {
+	f, err := Create(filename)
 	if err != nil {
 		return err
 	}
 	defer f.Close()
 
-	if _, err := f.Write(d); err != nil {
+	w := csv.NewWriter(f)
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	if err := w.WriteAll(rows); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// ToGraph writes the graph to the file in the format given by the file's extension.
+func ToGraph(g *graph.Graph, filename string) error {
+	format, err := graph.FormatFromPath(filename)
+	if err != nil {
+		return err
+	}
+
+	f, err := Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return g.Encode(f, format)
+}
+
+// Create creates the file and its parent folders, if they do not exist. A filename of "-"
+// refers to stdout, which is not closed when the returned writer is closed.
+func Create(filename string) (io.WriteCloser, error) {
+	if filename == "-" {
+		return nopCloser{os.Stdout}, nil
+	}
+
+	// create folder if not exists
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return nil, err
+	}
+
+	return os.Create(filename)
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
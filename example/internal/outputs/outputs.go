@@ -0,0 +1,122 @@
+package outputs
+
+import (
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/yusufaine/gocrawler"
+	"github.com/yusufaine/gocrawler/example/internal/filewriter"
+	"github.com/yusufaine/gocrawler/export"
+	"github.com/yusufaine/gocrawler/graph"
+	"github.com/yusufaine/gocrawler/warc"
+)
+
+// Outputs are the files that the examples can write besides their report, each of them is
+// disabled if its path is unset. It is embedded in the config of each example.
+type Outputs struct {
+	CorpusPath   string
+	EventsPath   string
+	ExportFormat string
+	ExportPath   string
+	GraphLevel   string
+	GraphPath    string
+	HARBodies    bool
+	HARPath      string
+	WARCDir      string
+	WARCMaxBytes int64
+}
+
+// Setup sets the WARC and HAR recorders, the event stream and the text corpus of the crawler
+// config, and returns a func that writes the HAR log and closes the files once the crawl and the report
+// are done. The name is used as the prefix of the WARC files.
+func (o *Outputs) Setup(name string, config *gocrawler.Config) func() {
+	var closers []func()
+	closeAll := func() {
+		for i := len(closers) - 1; i >= 0; i-- {
+			closers[i]()
+		}
+	}
+
+	// archive every HTTP exchange
+	if o.WARCDir != "" {
+		ww, err := warc.NewWriter(o.WARCDir, name, o.WARCMaxBytes)
+		if err != nil {
+			panic(err)
+		}
+		closers = append(closers, func() {
+			if err := ww.Close(); err != nil {
+				log.Error("unable to close WARC writer", "error", err)
+			}
+		})
+		config.Recorder = ww
+	}
+
+	// record every HTTP request for debugging
+	if o.HARPath != "" {
+		config.HARRecorder = gocrawler.NewHARRecorder(o.HARBodies)
+		closers = append(closers, func() {
+			if err := filewriter.ToJSON(config.HARRecorder.HAR(), o.HARPath); err != nil {
+				log.Error("unable to write HAR log", "file", o.HARPath, "error", err)
+			} else {
+				log.Info("exported HAR log", "file", o.HARPath)
+			}
+		})
+	}
+
+	// stream events as the crawl progresses so that long crawls can be monitored
+	if o.EventsPath != "" {
+		ew, err := filewriter.Create(o.EventsPath)
+		if err != nil {
+			closeAll()
+			panic(err)
+		}
+		closers = append(closers, func() { ew.Close() })
+		config.Events = ew
+	}
+
+	// the main text of the pages is written as they are crawled instead of being kept in memory
+	if o.CorpusPath != "" {
+		cw, err := filewriter.Create(o.CorpusPath)
+		if err != nil {
+			closeAll()
+			panic(err)
+		}
+		closers = append(closers, func() {
+			cw.Close()
+			log.Info("exported text corpus", "file", o.CorpusPath)
+		})
+		config.Corpus = cw
+	}
+
+	return closeAll
+}
+
+// Write exports the crawl results as tables and the link graph of the crawl.
+func (o *Outputs) Write(cr *gocrawler.Client, config *gocrawler.Config, start time.Time) {
+	if o.ExportPath != "" {
+		crawl := export.Crawl{
+			Seeds:     config.SeedURLs,
+			MaxDepth:  config.MaxDepth,
+			MaxRPS:    config.MaxRPS,
+			StartedAt: start,
+			Duration:  time.Since(start),
+		}
+		if err := export.Write(export.Format(o.ExportFormat), o.ExportPath, crawl, cr); err != nil {
+			log.Error("unable to export crawl results", "file", o.ExportPath, "error", err)
+		} else {
+			log.Info("exported crawl results", "file", o.ExportPath, "format", o.ExportFormat)
+		}
+	}
+
+	if o.GraphPath != "" {
+		g := graph.PageGraph(cr)
+		if o.GraphLevel == "host" {
+			g = graph.HostGraph(g)
+		}
+		if err := filewriter.ToGraph(g, o.GraphPath); err != nil {
+			log.Error("unable to export link graph", "file", o.GraphPath, "error", err)
+		} else {
+			log.Info("exported link graph", "file", o.GraphPath)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package linkchecker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/charmbracelet/log"
+	"github.com/yusufaine/gocrawler"
+	"github.com/yusufaine/gocrawler/internal/rhttp"
+	"golang.org/x/time/rate"
+)
+
+// max number of links being checked at the same time, the rate limit still applies
+const maxInFlight = 16
+
+// LinkStatus is the result of checking a single URL, Error is set if no response was received.
+type LinkStatus struct {
+	StatusCode int    `json:"status_code,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+func (ls LinkStatus) IsBroken() bool {
+	return ls.Error != "" || ls.StatusCode >= 400
+}
+
+// Check resolves the status of every unique outgoing link. Links that the crawler has already
+// requested reuse the crawler's result, the rest are checked with a HEAD request, falling back
+// to a GET request as some servers do not handle HEAD requests properly. Links to blacklisted
+// hosts are not checked. Links that were not checked because the context was cancelled are left
+// out of the statuses and counted as unchecked.
+func Check(ctx context.Context, config *Config, cr *gocrawler.Client, pageLinks map[string][]OutLink) (map[string]LinkStatus, int) {
+	hc := rhttp.New(
+		rhttp.WithBackoffPolicy(rhttp.ExponentialBackoff),
+		rhttp.WithMaxRetries(config.MaxRetries),
+		rhttp.WithRetryPolicy(rhttp.DefaultRetry),
+		rhttp.WithTimeout(config.Timeout),
+		rhttp.WithProxy(config.ProxyURL),
+	)
+	rl := rate.NewLimiter(rate.Limit(config.MaxRPS), 1)
+
+	var (
+		statuses = make(map[string]LinkStatus)
+		toCheck  []string
+	)
+	cr.AttemptMutex.RLock()
+	for _, outLinks := range pageLinks {
+		for _, ol := range outLinks {
+			if _, ok := statuses[ol.URL]; ok || isBlacklisted(config, ol.URL) {
+				continue
+			}
+			ai, ok := cr.Attempts[ol.URL]
+			switch {
+			case ok && ai.StatusCode > 0:
+				statuses[ol.URL] = LinkStatus{StatusCode: ai.StatusCode}
+			case ok && (ai.Outcome == gocrawler.OutcomeNetworkError || ai.Outcome == gocrawler.OutcomeRedirectError):
+				statuses[ol.URL] = LinkStatus{Error: ai.Error}
+			default:
+				// placeholder to dedup, replaced once checked
+				statuses[ol.URL] = LinkStatus{}
+				toCheck = append(toCheck, ol.URL)
+			}
+		}
+	}
+	cr.AttemptMutex.RUnlock()
+
+	log.Info("checking links", "count", len(toCheck), "reused", len(statuses)-len(toCheck))
+
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, maxInFlight)
+	)
+	for _, link := range toCheck {
+		if err := rl.Wait(ctx); err != nil {
+			break
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(link string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			ls, ok := checkLink(ctx, hc, link)
+			mu.Lock()
+			defer mu.Unlock()
+			if !ok {
+				delete(statuses, link)
+				return
+			}
+			statuses[link] = ls
+			if ls.IsBroken() {
+				log.Warn("broken link", "link", link, "status", ls.StatusCode, "error", ls.Error)
+			}
+		}(link)
+	}
+	wg.Wait()
+
+	// links that were not checked due to cancellation are not reported
+	var unchecked int
+	for _, link := range toCheck {
+		if ls, ok := statuses[link]; !ok || ls == (LinkStatus{}) {
+			delete(statuses, link)
+			unchecked++
+		}
+	}
+	if unchecked > 0 {
+		log.Warn("links were not checked", "count", unchecked)
+	}
+
+	return statuses, unchecked
+}
+
+// Returns false if the check was cancelled.
+func checkLink(ctx context.Context, hc *rhttp.Client, link string) (LinkStatus, bool) {
+	ls, err := doCheck(ctx, hc, http.MethodHead, link)
+	if err == nil && !ls.IsBroken() {
+		return ls, true
+	}
+	if ctx.Err() != nil {
+		return ls, false
+	}
+
+	ls, err = doCheck(ctx, hc, http.MethodGet, link)
+	if ctx.Err() != nil {
+		return ls, false
+	}
+	if err != nil {
+		ls.Error = err.Error()
+	}
+
+	return ls, true
+}
+
+func doCheck(ctx context.Context, hc *rhttp.Client, method, link string) (LinkStatus, error) {
+	req, err := http.NewRequestWithContext(ctx, method, link, nil)
+	if err != nil {
+		return LinkStatus{}, err
+	}
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return LinkStatus{}, err
+	}
+	if resp == nil {
+		return LinkStatus{}, errors.New("no response received")
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	return LinkStatus{StatusCode: resp.StatusCode}, nil
+}
+
+func isBlacklisted(config *Config, link string) bool {
+	parsedURL, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	_, ok := config.BlacklistHosts.Match(parsedURL.Host)
+	return ok
+}
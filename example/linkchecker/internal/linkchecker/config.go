@@ -0,0 +1,140 @@
+package linkchecker
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/yusufaine/gocrawler"
+	"github.com/yusufaine/gocrawler/export"
+	"github.com/yusufaine/gocrawler/hostrule"
+	"github.com/yusufaine/gocrawler/internal/logger"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatGitHub = "github"
+	FormatJSON   = "json"
+)
+
+type Config struct {
+	gocrawler.Config
+	ExportFormat string
+	ExportPath   string
+	Format       string
+	ReportPath   string
+}
+
+// SetupConfig wraps the gocrawler.Config and adds the report format and path where users can
+// specify how and where to export the broken links to.
+func SetupConfig() *Config {
+	var (
+		c       Config
+		blHosts string
+		seeds   string
+		proxy   string
+		verbose bool
+	)
+	flag.Int64Var(&c.MaxBodyBytes, "max-body", 10<<20, "Max bytes to read from a response body, larger bodies are truncated. <= 0 for no limit")
+	flag.IntVar(&c.MaxRedirects, "redirects", 10, "Max redirect hops to follow, < 0 disables following redirects")
+	flag.IntVar(&c.MaxRetries, "retries", 3, "Max retries for HTTP requests")
+	flag.BoolVar(&c.SameHostRedirects, "same-host-redirects", true, "Only follow redirects that stay on the same host while crawling")
+	flag.Float64Var(&c.MaxRPS, "rps", 20, "Max requests per second")
+	flag.DurationVar(&c.Timeout, "timeout", 10*time.Second, "Timeout for HTTP requests")
+	flag.StringVar(&c.Format, "format", FormatJSON, "Report format, one of: json, csv, github")
+	flag.StringVar(&c.ReportPath, "report", "", "Path to export report to, '-' for stdout. Defaults to 'linkcheck_<seed>.<format>', or stdout for github")
+	flag.StringVar(&c.ExportPath, "export", "", "Path to export the crawl results to as tables, disabled if unset")
+	flag.StringVar(&c.ExportFormat, "export-format", "sqlite", "Format of --export: 'sqlite' adds the crawl to the database at the path, 'csv' or 'parquet' write the pages, links and hosts tables to the folder at the path")
+	flag.StringVar(&blHosts, "bl", "", "Comma separated list of hosts whose links are not checked: example.com for the host and its subdomains (and without www. if it has it), exact:example.com for the host only, domain:example.com for every host of its registrable domain, or a glob such as *.cdn.*")
+	flag.StringVar(&proxy, "proxy", "", "Proxy URL")
+	flag.StringVar(&seeds, "seed", "", "Comma separated seed URL(s), required (e.g https://example.com)")
+	flag.BoolVar(&verbose, "verbose", false, "Verbose logging, includes short caller info")
+	flag.Parse()
+	logger.Setup(verbose)
+
+	// linkchecker crawls indefinitely as long as the host is the same
+	c.MaxDepth = math.MaxInt
+
+	c.SeedURLs = strings.Split(seeds, ",")
+
+	// page bodies are only needed to extract links
+	c.ContentStore = gocrawler.NullStore{}
+
+	// Parse proxy URL, if any
+	parsedProxy, _ := url.Parse(proxy)
+	c.ProxyURL = parsedProxy
+
+	var err error
+	if c.BlacklistHosts, err = hostrule.ParseRules(blHosts); err != nil {
+		panic(fmt.Sprintf("invalid --bl: %v", err))
+	}
+
+	c.mustValidate()
+
+	return &c
+}
+
+func (c *Config) mustValidate() {
+	if len(c.SeedURLs) == 0 || c.SeedURLs[0] == "" {
+		panic("--seed is required!")
+	}
+
+	parsedSeed, err := url.Parse(c.SeedURLs[0])
+	if err != nil {
+		panic("--seed is not valid!")
+	}
+
+	switch c.Format {
+	case FormatJSON, FormatCSV:
+		if c.ReportPath == "" {
+			c.ReportPath = fmt.Sprintf("linkcheck_%s.%s", parsedSeed.Host, c.Format)
+		}
+	case FormatGitHub:
+		if c.ReportPath == "" {
+			c.ReportPath = "-"
+		}
+	default:
+		panic("--format must be one of: json, csv, github")
+	}
+
+	if c.MaxRPS <= 0 {
+		panic("--rps must be > 0")
+	}
+	if c.Timeout <= 0 {
+		panic("--timeout must be > 0")
+	}
+	if c.MaxRetries < 0 {
+		panic("--retries must be >= 0")
+	}
+	if _, err := export.ParseFormat(c.ExportFormat); err != nil {
+		panic(err)
+	}
+
+	if c.MaxRPS > 20 {
+		log.Warn("rps is set tp greater than 20 may cause unexpected behaviour such as rate limiting and IP bans")
+	}
+	if len(c.ProxyURL.String()) > 0 {
+		log.Warn("proxy is set, this may affect the network info collected")
+	}
+}
+
+func (c *Config) PrintConfig() {
+	log.Info("Running with config (ctrl-c to cancel crawling): ")
+	log.Info(" ", "seed", strings.Join(c.SeedURLs, ", "))
+	log.Info(" ", "proxy", c.ProxyURL)
+	log.Info(" ", "blacklist", strings.Join(c.BlacklistHosts.Strings(), ", "))
+	log.Info(" ", "max body bytes", c.MaxBodyBytes)
+	log.Info(" ", "redirects", c.MaxRedirects)
+	log.Info(" ", "same host redirects", c.SameHostRedirects)
+	log.Info(" ", "retries", c.MaxRetries)
+	log.Info(" ", "rps", c.MaxRPS)
+	log.Info(" ", "timeout", c.Timeout)
+	log.Info(" ", "format", c.Format)
+	log.Info(" ", "report", c.ReportPath)
+	log.Info(" ", "export", c.ExportPath)
+	log.Info(" ", "export format", c.ExportFormat)
+}
@@ -0,0 +1,129 @@
+package linkchecker
+
+import (
+	"bytes"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/charmbracelet/log"
+	"github.com/yusufaine/gocrawler"
+)
+
+// Elements and the attribute that holds the URL to be checked
+var linkAttrs = []struct {
+	tag  string
+	attr string
+}{
+	{"a", "href"},
+	{"link", "href"},
+	{"img", "src"},
+	{"script", "src"},
+	{"iframe", "src"},
+	{"source", "src"},
+	{"video", "src"},
+	{"audio", "src"},
+}
+
+// OutLink is a link found on a crawled page that should be checked
+type OutLink struct {
+	URL  string `json:"url"`
+	Tag  string `json:"tag"`
+	Text string `json:"text"`
+}
+
+// Collector keeps track of every outgoing link found on each crawled page, including assets
+// and links to other hosts.
+type Collector struct {
+	mu    sync.Mutex
+	links map[string][]OutLink
+}
+
+func NewCollector() *Collector {
+	return &Collector{links: make(map[string][]OutLink)}
+}
+
+// LinkExtractor collects all outgoing links of the page, but only returns links to pages on
+// the same host so that the crawler does not recurse off-site.
+func (col *Collector) LinkExtractor(c *gocrawler.Client, currLink string, resp []byte) []string {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(resp))
+	if err != nil {
+		log.Error("unable to parse response body", "error", err)
+		return nil
+	}
+
+	currURL, err := url.Parse(currLink)
+	if err != nil {
+		return nil
+	}
+
+	var (
+		outLinks []OutLink
+		seen     = make(map[OutLink]struct{})
+		crawlSet = make(map[string]struct{})
+	)
+	for _, la := range linkAttrs {
+		doc.Find(la.tag).Each(func(i int, s *goquery.Selection) {
+			ref, ok := s.Attr(la.attr)
+			if !ok {
+				return
+			}
+
+			outURL, err := currURL.Parse(strings.TrimSpace(ref))
+			if err != nil || (outURL.Scheme != "http" && outURL.Scheme != "https") {
+				return
+			}
+			outURL.Fragment = ""
+
+			ol := OutLink{
+				URL:  outURL.String(),
+				Tag:  la.tag,
+				Text: anchorText(s),
+			}
+			if _, ok := seen[ol]; ok {
+				return
+			}
+			seen[ol] = struct{}{}
+			outLinks = append(outLinks, ol)
+
+			if la.tag == "a" && outURL.Host == currURL.Host {
+				crawlSet[ol.URL] = struct{}{}
+			}
+		})
+	}
+
+	col.mu.Lock()
+	col.links[currLink] = outLinks
+	col.mu.Unlock()
+
+	links := make([]string, 0, len(crawlSet))
+	for k := range crawlSet {
+		links = append(links, k)
+	}
+	slices.Sort(links)
+
+	return links
+}
+
+// Uses the text of the element, falling back to the alt and title attributes, with the
+// whitespace collapsed.
+func anchorText(s *goquery.Selection) string {
+	text := strings.Join(strings.Fields(s.Text()), " ")
+	if text == "" {
+		text = s.AttrOr("alt", s.AttrOr("title", ""))
+	}
+	return text
+}
+
+// Links returns a copy of the outgoing links of each crawled page.
+func (col *Collector) Links() map[string][]OutLink {
+	col.mu.Lock()
+	defer col.mu.Unlock()
+	links := make(map[string][]OutLink, len(col.links))
+	for k, v := range col.links {
+		links[k] = slices.Clone(v)
+	}
+	return links
+}
@@ -0,0 +1,169 @@
+package linkchecker
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/yusufaine/gocrawler/example/internal/filewriter"
+)
+
+// BrokenLink is an outgoing link that returned a 4xx/5xx status code or could not be reached
+type BrokenLink struct {
+	OutLink
+	LinkStatus
+	Internal bool `json:"internal"`
+}
+
+type ReportFormat struct {
+	Seeds        []string `json:"seeds"`
+	MaxRPS       float64  `json:"max_rps"`
+	CrawlTime    string   `json:"crawl_time"`
+	PagesCrawled int      `json:"pages_crawled"`
+	LinksChecked int      `json:"links_checked"`
+	BrokenCount  int      `json:"broken_count"`
+
+	// keyed by the page that the broken links were found on
+	BrokenLinks map[string][]BrokenLink `json:"broken_links"`
+}
+
+// Generates a report of the broken links grouped by the page they were found on, in the
+// configured format, and returns the number of broken links found.
+func Generate(config *Config, pageLinks map[string][]OutLink, statuses map[string]LinkStatus, elapsed time.Duration) int {
+	report := ReportFormat{
+		Seeds:        config.SeedURLs,
+		MaxRPS:       config.MaxRPS,
+		CrawlTime:    elapsed.String(),
+		PagesCrawled: len(pageLinks),
+		LinksChecked: len(statuses),
+		BrokenLinks:  make(map[string][]BrokenLink),
+	}
+	for page, outLinks := range pageLinks {
+		pageURL, _ := url.Parse(page)
+		for _, ol := range outLinks {
+			ls, ok := statuses[ol.URL]
+			if !ok || !ls.IsBroken() {
+				continue
+			}
+			outURL, _ := url.Parse(ol.URL)
+			report.BrokenLinks[page] = append(report.BrokenLinks[page], BrokenLink{
+				OutLink:    ol,
+				LinkStatus: ls,
+				Internal:   pageURL != nil && outURL != nil && pageURL.Host == outURL.Host,
+			})
+			report.BrokenCount++
+		}
+	}
+
+	var err error
+	switch config.Format {
+	case FormatCSV:
+		err = filewriter.ToCSV(
+			[]string{"source", "url", "tag", "text", "internal", "status_code", "error"},
+			report.csvRows(),
+			config.ReportPath,
+		)
+	case FormatGitHub:
+		err = report.writeGitHub(config.ReportPath)
+	default:
+		err = filewriter.ToJSON(report, config.ReportPath)
+	}
+	if err != nil {
+		log.Error("unable to write to file", "file", config.ReportPath, "error", err)
+	} else {
+		log.Info("exported broken link report", "file", config.ReportPath, "broken", report.BrokenCount)
+	}
+
+	return report.BrokenCount
+}
+
+func (r *ReportFormat) sortedPages() []string {
+	pages := make([]string, 0, len(r.BrokenLinks))
+	for k := range r.BrokenLinks {
+		pages = append(pages, k)
+	}
+	slices.Sort(pages)
+	return pages
+}
+
+func (r *ReportFormat) csvRows() [][]string {
+	var rows [][]string
+	for _, page := range r.sortedPages() {
+		for _, bl := range r.BrokenLinks[page] {
+			statusCode := ""
+			if bl.StatusCode > 0 {
+				statusCode = strconv.Itoa(bl.StatusCode)
+			}
+			rows = append(rows, []string{
+				page,
+				bl.URL,
+				bl.Tag,
+				bl.Text,
+				strconv.FormatBool(bl.Internal),
+				statusCode,
+				bl.Error,
+			})
+		}
+	}
+	return rows
+}
+
+// Writes one error annotation per broken link using GitHub Actions workflow commands, with
+// the annotations of each page collapsed into its own group in the job log.
+func (r *ReportFormat) writeGitHub(filename string) error {
+	f, err := filewriter.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	for _, page := range r.sortedPages() {
+		if _, err := fmt.Fprintf(f, "::group::%s\n", escapeData(page)); err != nil {
+			return err
+		}
+		for _, bl := range r.BrokenLinks[page] {
+			if err := writeAnnotation(f, page, bl); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(f, "::endgroup::"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeAnnotation(w io.Writer, page string, bl BrokenLink) error {
+	reason := bl.Error
+	if reason == "" {
+		reason = fmt.Sprintf("%d %s", bl.StatusCode, http.StatusText(bl.StatusCode))
+	}
+	msg := fmt.Sprintf("%s links to %s (%s)", page, bl.URL, reason)
+	if bl.Text != "" {
+		msg += fmt.Sprintf(" with text %q", bl.Text)
+	}
+	_, err := fmt.Fprintf(w, "::error title=%s::%s\n", escapeProperty("Broken link"), escapeData(msg))
+	return err
+}
+
+// Escapes the message of a workflow command
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// Escapes the property values of a workflow command
+func escapeProperty(s string) string {
+	return strings.NewReplacer(
+		"%", "%25",
+		"\r", "%0D",
+		"\n", "%0A",
+		":", "%3A",
+		",", "%2C",
+	).Replace(s)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/yusufaine/gocrawler"
+	"github.com/yusufaine/gocrawler/example/linkchecker/internal/linkchecker"
+	"github.com/yusufaine/gocrawler/export"
+)
+
+func main() {
+	// exit with a non-zero code if any broken links were found so that it can be used in CI
+	os.Exit(run())
+}
+
+func run() (exitCode int) {
+	// ensures that the data collected so far is exported when the user terminates the program
+	// (e.g. ctrl+c)
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	defer func() {
+		if r := recover(); r != nil {
+			log.Fatal(r)
+		}
+	}()
+
+	// linkchecker.Config embeds gocrawler.Config
+	config := linkchecker.SetupConfig()
+	config.PrintConfig()
+	time.Sleep(3 * time.Second)
+	start := time.Now()
+
+	// Only HTML pages on the same host are crawled, every other link is checked after
+	col := linkchecker.NewCollector()
+	cr := gocrawler.New(ctx,
+		&config.Config,
+		[]gocrawler.Matcher{gocrawler.ResponseMatcher(gocrawler.IsHtmlContent)},
+		col.LinkExtractor,
+	)
+	defer func() {
+		pageLinks := col.Links()
+		statuses, unchecked := linkchecker.Check(ctx, config, cr, pageLinks)
+		log.Info("generating broken link report", "file", config.ReportPath)
+		if broken := linkchecker.Generate(config, pageLinks, statuses, time.Since(start)); broken > 0 {
+			exitCode = 1
+		}
+		// the report of an interrupted run is incomplete, so it does not pass
+		if ctx.Err() != nil || unchecked > 0 {
+			log.Warn("report is incomplete", "interrupted", ctx.Err() != nil, "unchecked", unchecked)
+			exitCode = 1
+		}
+
+		if config.ExportPath != "" {
+			crawl := export.Crawl{
+				Seeds:     config.SeedURLs,
+				MaxDepth:  config.MaxDepth,
+				MaxRPS:    config.MaxRPS,
+				StartedAt: start,
+				Duration:  time.Since(start),
+			}
+			if err := export.Write(export.Format(config.ExportFormat), config.ExportPath, crawl, cr); err != nil {
+				log.Error("unable to export crawl results", "file", config.ExportPath, "error", err)
+			} else {
+				log.Info("exported crawl results", "file", config.ExportPath, "format", config.ExportFormat)
+			}
+		}
+	}()
+
+	go func() {
+		defer func() {
+			cancel()
+			close(sig)
+		}()
+
+		<-ctx.Done()
+		fmt.Println()
+		log.Info("stopping crawler, press ctrl+c again to force quit", "signal", <-sig)
+	}()
+
+	var wg sync.WaitGroup
+	for _, seed := range config.SeedURLs {
+		wg.Add(1)
+		go func(seed string) {
+			defer wg.Done()
+			cr.Crawl(ctx, 0, seed, "")
+		}(seed)
+	}
+	wg.Wait()
+	log.Info("crawl completed")
+
+	return 0
+}
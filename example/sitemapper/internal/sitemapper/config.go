@@ -5,18 +5,29 @@ import (
 	"fmt"
 	"math"
 	"net/url"
-	"slices"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/yusufaine/gocrawler"
+	"github.com/yusufaine/gocrawler/example/internal/outputs"
+	"github.com/yusufaine/gocrawler/export"
+	"github.com/yusufaine/gocrawler/fingerprint"
+	"github.com/yusufaine/gocrawler/graph"
+	"github.com/yusufaine/gocrawler/hostrule"
 	"github.com/yusufaine/gocrawler/internal/logger"
+	"github.com/yusufaine/gocrawler/scope"
+	"github.com/yusufaine/gocrawler/scrape"
 )
 
 type Config struct {
 	gocrawler.Config
-	ReportPath string
+	outputs.Outputs
+	ContentDir   string
+	MixedContent bool
+	PreviousPath string
+	ReportPath   string
+	RulesPath    string
 }
 
 // SetupConfig wraps the gocrawler.Config and adds an additional report path field
@@ -28,11 +39,41 @@ func SetupConfig() *Config {
 		proxy   string
 		verbose bool
 	)
+	c.Scope = &scope.Scope{}
+	flag.Int64Var(&c.MaxBodyBytes, "max-body", 10<<20, "Max bytes to read from a response body, larger bodies are truncated. <= 0 for no limit")
+	flag.IntVar(&c.MaxRedirects, "redirects", 10, "Max redirect hops to follow, < 0 disables following redirects")
 	flag.IntVar(&c.MaxRetries, "retries", 3, "Max retries for HTTP requests")
+	flag.BoolVar(&c.SameHostRedirects, "same-host-redirects", true, "Only follow redirects that stay on the same host")
+	flag.IntVar(&c.Traps.MaxPathDepth, "max-path-depth", 20, "Max number of segments in the path of a link, longer paths are treated as crawler traps. <= 0 for no limit")
+	flag.IntVar(&c.Traps.MaxRepeatedSegments, "max-repeated-segments", 3, "Max number of times the same segment can appear in the path of a link, e.g. /a/b/a/b/a/b. <= 0 for no limit")
+	flag.IntVar(&c.Traps.MaxURLLength, "max-url-length", 2048, "Max length of a link, longer links are treated as crawler traps. <= 0 for no limit")
+	flag.IntVar(&c.Traps.MaxQueryVariants, "max-query-variants", 100, "Max number of distinct query strings to crawl for the same path. <= 0 for no limit")
+	flag.IntVar(&c.Traps.MaxPagesPerHost, "max-host-pages", 0, "Max number of distinct links to follow to the same host. <= 0 for no limit")
+	flag.IntVar(&c.NearDupDistance, "near-dup-distance", fingerprint.DefaultDistance, "Max bits that the SimHash of near-duplicate pages can differ by, < 0 to only detect exact duplicates")
 	flag.Float64Var(&c.MaxRPS, "rps", 20, "Max requests per second")
+	flag.BoolVar(&c.MixedContent, "mixed-content", false, "Also extract links from XML sitemaps, RSS/Atom feeds, JSON, plain text and PDFs, not just HTML")
+	flag.BoolVar(&c.ExtractPageMeta, "page-meta", false, "Extract the title, description, canonical URL, robots directives, headings, Open Graph/Twitter cards and JSON-LD of HTML pages, honouring robots nofollow and following canonical URLs")
+	flag.BoolVar(&c.ExtractText, "text", false, "Extract the main text of HTML pages without navigation, footers, ads and scripts, with its word count and reading time")
+	flag.StringVar(&c.CorpusPath, "corpus", "", "Path to write the main text of the pages to as a JSON Lines text corpus, implies --text, disabled if unset")
+	flag.StringVar(&c.RulesPath, "rules", "", "Path to a YAML or JSON file of scraping rules, the records extracted from matching HTML pages are added to their page info, disabled if unset")
+	flag.BoolVar(&c.SkipNearDups, "skip-near-dups", false, "Do not follow the links of pages that are near-duplicates of a page already visited")
 	flag.DurationVar(&c.Timeout, "timeout", 10*time.Second, "Timeout for HTTP requests")
 	flag.StringVar(&c.ReportPath, "report", "", "Path to export report to. Defaults to 'sitemap_<seed>.json")
+	flag.StringVar(&c.ContentDir, "content-dir", "", "Folder to store gzipped page bodies in, bodies are discarded after extracting links if unset")
+	flag.StringVar(&c.EventsPath, "events", "", "Path to stream page, host and error events to as NDJSON, \"-\" for stdout, disabled if unset")
+	flag.StringVar(&c.GraphPath, "graph", "", "Path to export the link graph to, the format is chosen by the extension (.graphml, .dot, .gv or .gexf), disabled if unset")
+	flag.StringVar(&c.GraphLevel, "graph-level", "page", "Level of the link graph, 'page' or 'host'")
+	flag.StringVar(&c.ExportPath, "export", "", "Path to export the crawl results to as tables, disabled if unset")
+	flag.StringVar(&c.ExportFormat, "export-format", "sqlite", "Format of --export: 'sqlite' adds the crawl to the database at the path, 'csv' or 'parquet' write the pages, links and hosts tables to the folder at the path")
+	flag.StringVar(&c.HARPath, "har", "", "Path to write a HAR log of every HTTP request to, for debugging in browser devtools, disabled if unset")
+	flag.BoolVar(&c.HARBodies, "har-bodies", false, "Include response bodies in the HAR log, bodies are kept in memory until the crawl ends")
+	flag.StringVar(&c.PreviousPath, "previous", "", "Report of a previous crawl to recrawl incrementally, known pages are requested conditionally and carried forward if unchanged. --seed defaults to the seeds of the previous crawl")
+	flag.BoolVar(&c.SkipUnchanged, "skip-unchanged", false, "With --previous, carry forward the known pages linked from unchanged pages without requesting them")
+	flag.StringVar(&c.WARCDir, "warc-dir", "", "Folder to archive every HTTP exchange to as WARC files, disabled if unset")
+	flag.Int64Var(&c.WARCMaxBytes, "warc-max-size", 1<<30, "Max bytes of each WARC file before a new file is started")
 	flag.StringVar(&proxy, "proxy", "", "Proxy URL")
+	flag.Func("include", "Scope rule of links to crawl, can be repeated to crawl links that match any rule (e.g. \"host=.example.com path=/blog/ depth=3\"). Keys are host, path, path-regex, scheme, ext, query and depth, defaults to the host of the seed", scope.RuleFlag(&c.Scope.Include))
+	flag.Func("exclude", "Scope rule of links not to crawl, can be repeated (e.g. \"ext=pdf,zip\")", scope.RuleFlag(&c.Scope.Exclude))
 	flag.StringVar(&seed, "seed", "", "Seed URL, required (e.g https://example.com)")
 	flag.BoolVar(&verbose, "verbose", false, "Verbose logging, includes short caller info")
 	flag.Parse()
@@ -41,14 +82,53 @@ func SetupConfig() *Config {
 	// sitemapper crawls indefinitely as long as the host is the same
 	c.MaxDepth = math.MaxInt
 
+	// an incremental recrawl starts from where the previous crawl started, unless told otherwise
+	if c.PreviousPath != "" {
+		previous, err := gocrawler.LoadPreviousPages(c.PreviousPath)
+		if err != nil {
+			panic(fmt.Sprintf("unable to load --previous: %v", err))
+		}
+		c.Previous = previous
+		if seed == "" {
+			seed = strings.Join(gocrawler.PreviousSeeds(previous), ",")
+		}
+	}
 	c.SeedURLs = strings.Split(seed, ",")
 
+	if c.MixedContent {
+		c.ContentHandlers = gocrawler.DefaultContentHandlers()
+	}
+	if c.CorpusPath != "" {
+		c.ExtractText = true
+	}
+	// the report has the page that each page duplicates, which also keeps them out of the corpus
+	c.DetectDuplicates = true
+	if c.RulesPath != "" {
+		rules, err := scrape.Load(c.RulesPath)
+		if err != nil {
+			panic(fmt.Sprintf("unable to load --rules: %v", err))
+		}
+		c.ScrapeRules = rules
+	}
+
+	// page bodies are not used in the report, keep them only if asked to
+	c.ContentStore = gocrawler.NullStore{}
+	if c.ContentDir != "" {
+		c.ContentStore = gocrawler.NewFSStore(c.ContentDir)
+	}
+
 	// Parse proxy URL, if any
 	parsedProxy, _ := url.Parse(proxy)
 	c.ProxyURL = parsedProxy
 
 	c.mustValidate()
 
+	// sitemapper only maps the host of the seed, unless told otherwise
+	if len(c.Scope.Include) == 0 {
+		parsedSeed, _ := url.Parse(c.SeedURLs[0])
+		c.Scope.Include = []scope.Rule{{Hosts: hostrule.Rules{{Host: parsedSeed.Hostname(), Kind: hostrule.Exact}}}}
+	}
+
 	return &c
 }
 
@@ -79,6 +159,17 @@ func (c *Config) mustValidate() {
 	if c.MaxRetries < 0 {
 		panic("--retries must be >= 0")
 	}
+	if _, err := export.ParseFormat(c.ExportFormat); err != nil {
+		panic(err)
+	}
+	if c.GraphPath != "" {
+		if _, err := graph.FormatFromPath(c.GraphPath); err != nil {
+			panic(err)
+		}
+	}
+	if c.GraphLevel != "page" && c.GraphLevel != "host" {
+		panic("--graph-level must be 'page' or 'host'")
+	}
 
 	if c.MaxRPS > 20 {
 		log.Warn("rps is set tp greater than 20 may cause unexpected behaviour such as rate limiting and IP bans")
@@ -89,17 +180,38 @@ func (c *Config) mustValidate() {
 }
 
 func (c *Config) PrintConfig() {
-	blHosts := make([]string, 0, len(c.BlacklistHosts))
-	for k := range c.BlacklistHosts {
-		blHosts = append(blHosts, k)
-	}
-	slices.Sort(blHosts)
-
 	log.Info("Running with config (ctrl-c to cancel crawling): ")
 	log.Info(" ", "seed", strings.Join(c.SeedURLs, ", "))
 	log.Info(" ", "proxy", c.ProxyURL)
+	log.Info(" ", "include", scope.FormatRules(c.Scope.Include))
+	log.Info(" ", "exclude", scope.FormatRules(c.Scope.Exclude))
+	log.Info(" ", "content dir", c.ContentDir)
+	log.Info(" ", "max body bytes", c.MaxBodyBytes)
+	log.Info(" ", "redirects", c.MaxRedirects)
+	log.Info(" ", "same host redirects", c.SameHostRedirects)
 	log.Info(" ", "retries", c.MaxRetries)
 	log.Info(" ", "rps", c.MaxRPS)
+	log.Info(" ", "max path depth", c.Traps.MaxPathDepth)
+	log.Info(" ", "max repeated segments", c.Traps.MaxRepeatedSegments)
+	log.Info(" ", "max url length", c.Traps.MaxURLLength)
+	log.Info(" ", "max query variants", c.Traps.MaxQueryVariants)
+	log.Info(" ", "max host pages", c.Traps.MaxPagesPerHost)
+	log.Info(" ", "near dup distance", c.NearDupDistance)
+	log.Info(" ", "skip near dups", c.SkipNearDups)
+	log.Info(" ", "mixed content", c.MixedContent)
+	log.Info(" ", "page meta", c.ExtractPageMeta)
+	log.Info(" ", "text", c.ExtractText)
+	log.Info(" ", "corpus", c.CorpusPath)
+	log.Info(" ", "rules", c.RulesPath)
 	log.Info(" ", "timeout", c.Timeout)
 	log.Info(" ", "report", c.ReportPath)
+	log.Info(" ", "warc dir", c.WARCDir)
+	log.Info(" ", "previous", c.PreviousPath)
+	log.Info(" ", "skip unchanged", c.SkipUnchanged)
+	log.Info(" ", "events", c.EventsPath)
+	log.Info(" ", "export", c.ExportPath)
+	log.Info(" ", "export format", c.ExportFormat)
+	log.Info(" ", "har", c.HARPath)
+	log.Info(" ", "graph", c.GraphPath)
+	log.Info(" ", "graph level", c.GraphLevel)
 }
@@ -6,7 +6,9 @@ import (
 
 	"github.com/charmbracelet/log"
 	"github.com/yusufaine/gocrawler"
+	"github.com/yusufaine/gocrawler/analysis"
 	"github.com/yusufaine/gocrawler/example/internal/filewriter"
+	"github.com/yusufaine/gocrawler/fingerprint"
 )
 
 type ReportFormat struct {
@@ -14,21 +16,48 @@ type ReportFormat struct {
 	MaxRPS    float64 `json:"max_rps"`
 	CrawlTime string  `json:"crawl_time"`
 
+	Transfer        gocrawler.TransferInfo             `json:"transfer"`
 	VisitedNetInfo  map[string][]gocrawler.NetworkInfo `json:"network_info"`
 	VisitedPageResp map[string]gocrawler.PageInfo      `json:"page_info"`
+	RedirectChains  map[string][]gocrawler.RedirectHop `json:"redirect_chains"`
+	Attempts        map[string]gocrawler.AttemptInfo   `json:"attempts"`
+	DuplicateGroups [][]string                         `json:"duplicate_clusters"`
+	Traps           map[string]gocrawler.AttemptInfo   `json:"traps"`
+	Sitemap         []string                           `json:"sitemap,omitempty"`
 }
 
-// Generates a report in JSON format from the crawler client and config. The report contains
-// the initial crawler info, the network info for each host visited, and the page info for
-// each page visited such as all the links found in the page if the link belongs to the same
-// host as the seed URL.
+// Generates a report in JSON format from the crawler client and config. The report contains the
+// initial crawler info, the bytes of the bodies transferred and decoded, the network info for each
+// host visited, and the page info for each page visited such as all the links found in the page if
+// the link belongs to the same host as the seed URL, the links skipped as crawler traps, and the
+// clusters of pages that are duplicates or near-duplicates of each other. If page metadata is
+// extracted, the report also lists the pages that belong in a sitemap.
 func Generate(config *Config, cr *gocrawler.Client, elapsed time.Duration) {
+	dupDistance := config.NearDupDistance
+	if dupDistance == 0 {
+		dupDistance = fingerprint.DefaultDistance
+	}
+
 	report := ReportFormat{
 		Seed:            config.SeedURLs[0],
 		MaxRPS:          config.MaxRPS,
 		CrawlTime:       elapsed.String(),
+		Transfer:        cr.TransferTotals(),
 		VisitedNetInfo:  cr.VisitedNetInfo,
 		VisitedPageResp: cr.VisitedPageInfo,
+		RedirectChains:  cr.VisitedRedirects,
+		Attempts:        cr.Attempts,
+		DuplicateGroups: analysis.DuplicateClusters(cr.VisitedPageInfo, dupDistance),
+		Traps:           cr.Traps(),
+	}
+	// with page metadata, pages that are noindex or have another canonical URL are not listed
+	if config.ExtractPageMeta {
+		for link, pi := range cr.VisitedPageInfo {
+			if pi.PageMeta.Indexable(link) {
+				report.Sitemap = append(report.Sitemap, link)
+			}
+		}
+		slices.Sort(report.Sitemap)
 	}
 	for k, v := range report.VisitedNetInfo {
 		for i, v1 := range v {
@@ -40,11 +69,7 @@ func Generate(config *Config, cr *gocrawler.Client, elapsed time.Duration) {
 			slices.Sort(v1.VisitedPaths)
 			report.VisitedNetInfo[k][i] = v1
 
-			visitedCount := int64(len(v1.VisitedPaths))
-			if visitedCount == 0 {
-				visitedCount = 1
-			}
-			v1.AvgResponseMs = v1.TotalResponseTimeMs / visitedCount
+			v1.AvgResponseMs = v1.TotalResponseTimeMs / int64(max(v1.RequestCount, 1))
 			report.VisitedNetInfo[k][i] = v1
 		}
 	}
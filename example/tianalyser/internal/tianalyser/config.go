@@ -1,21 +1,35 @@
 package tianalyser
 
 import (
+	_ "embed"
 	"flag"
 	"math"
 	"net/url"
-	"slices"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/yusufaine/gocrawler"
+	"github.com/yusufaine/gocrawler/example/internal/outputs"
+	"github.com/yusufaine/gocrawler/export"
+	"github.com/yusufaine/gocrawler/hostrule"
 	"github.com/yusufaine/gocrawler/internal/logger"
+	"github.com/yusufaine/gocrawler/scope"
+	"github.com/yusufaine/gocrawler/scrape"
 )
 
+// The default scraping rules of the TI statistics, which can be replaced with --rules
+//
+//go:embed ti_rules.yaml
+var defaultRules []byte
+
 type Config struct {
 	gocrawler.Config
+	outputs.Outputs
+	ContentDir string
 	ReportPath string
+	RulesPath  string
 }
 
 // Read config from flags to setup the crawler
@@ -25,10 +39,25 @@ func SetupConfig() *Config {
 		proxy   string
 		verbose bool
 	)
+	c.Scope = &scope.Scope{}
+	flag.Int64Var(&c.MaxBodyBytes, "max-body", 10<<20, "Max bytes to read from a response body, larger bodies are truncated. <= 0 for no limit")
+	flag.IntVar(&c.MaxRedirects, "redirects", 10, "Max redirect hops to follow, < 0 disables following redirects")
 	flag.IntVar(&c.MaxRetries, "retries", 3, "Max retries for HTTP requests")
+	flag.BoolVar(&c.SameHostRedirects, "same-host-redirects", false, "Only follow redirects that stay on the same host")
 	flag.Float64Var(&c.MaxRPS, "rps", 0.3, "Max requests per second")
 	flag.DurationVar(&c.Timeout, "timeout", 10*time.Second, "Timeout for HTTP requests")
 	flag.StringVar(&c.ReportPath, "report", "ti_stats.json", "Path to export report to")
+	flag.StringVar(&c.RulesPath, "rules", "", "Path to a YAML or JSON file of scraping rules that replace the default rules of the country representation tables of TI pages, the country_representation records of the ti_stats rule set are written to the report")
+	flag.StringVar(&c.ContentDir, "content-dir", "", "Folder to store gzipped page bodies in, bodies are kept in memory if unset")
+	flag.StringVar(&c.EventsPath, "events", "", "Path to stream page, host and error events to as NDJSON, \"-\" for stdout, disabled if unset")
+	flag.StringVar(&c.ExportPath, "export", "", "Path to export the crawl results to as tables, disabled if unset")
+	flag.StringVar(&c.ExportFormat, "export-format", "sqlite", "Format of --export: 'sqlite' adds the crawl to the database at the path, 'csv' or 'parquet' write the pages, links and hosts tables to the folder at the path")
+	flag.StringVar(&c.HARPath, "har", "", "Path to write a HAR log of every HTTP request to, for debugging in browser devtools, disabled if unset")
+	flag.BoolVar(&c.HARBodies, "har-bodies", false, "Include response bodies in the HAR log, bodies are kept in memory until the crawl ends")
+	flag.StringVar(&c.WARCDir, "warc-dir", "", "Folder to archive every HTTP exchange to as WARC files, disabled if unset")
+	flag.Int64Var(&c.WARCMaxBytes, "warc-max-size", 1<<30, "Max bytes of each WARC file before a new file is started")
+	flag.Func("include", "Scope rule of links to crawl, can be repeated to crawl links that match any rule (e.g. \"host=.example.com path=/blog/ depth=3\"). Keys are host, path, path-regex, scheme, ext, query and depth, defaults to the pages of The International on Liquipedia", scope.RuleFlag(&c.Scope.Include))
+	flag.Func("exclude", "Scope rule of links not to crawl, can be repeated (e.g. \"ext=pdf,zip\")", scope.RuleFlag(&c.Scope.Exclude))
 	flag.StringVar(&proxy, "proxy", "", "Proxy URL (e.g http://localhost:8080)")
 	flag.BoolVar(&verbose, "verbose", false, "For devs -- verbose logging, includes debug and short caller info")
 	flag.Parse()
@@ -36,6 +65,29 @@ func SetupConfig() *Config {
 
 	c.MaxDepth = math.MaxInt
 
+	// only the pages of The International are analysed, unless told otherwise
+	if len(c.Scope.Include) == 0 {
+		c.Scope.Include = []scope.Rule{{
+			Hosts:       hostrule.Rules{{Host: "liquipedia.net", Kind: hostrule.Exact}},
+			PathRegexps: []*regexp.Regexp{regexp.MustCompile("dota2/The_International/")},
+		}}
+	}
+
+	// the TI statistics are scraped from the pages as they are crawled
+	var err error
+	if c.RulesPath != "" {
+		c.ScrapeRules, err = scrape.Load(c.RulesPath)
+	} else {
+		c.ScrapeRules, err = scrape.ParseYAML(defaultRules)
+	}
+	if err != nil {
+		panic(err)
+	}
+
+	if c.ContentDir != "" {
+		c.ContentStore = gocrawler.NewFSStore(c.ContentDir)
+	}
+
 	// Parse proxy URL, if any
 	c.ProxyURL, _ = url.Parse(proxy)
 
@@ -57,6 +109,9 @@ func (c *Config) mustValidate() {
 	if c.MaxRetries < 0 {
 		panic("--retries must be >= 0")
 	}
+	if _, err := export.ParseFormat(c.ExportFormat); err != nil {
+		panic(err)
+	}
 
 	if c.MaxRPS > 20 {
 		log.Warn("rps is set tp greater than 20 may cause unexpected behaviour such as rate limiting and IP bans")
@@ -68,17 +123,23 @@ func (c *Config) mustValidate() {
 
 // Sanity check
 func (c *Config) PrintConfig() {
-	blHosts := make([]string, 0, len(c.BlacklistHosts))
-	for k := range c.BlacklistHosts {
-		blHosts = append(blHosts, k)
-	}
-	slices.Sort(blHosts)
-
 	log.Info("Running with config (ctrl-c to cancel crawling): ")
 	log.Info(" ", "seed", strings.Join(c.SeedURLs, ", "))
+	log.Info(" ", "include", scope.FormatRules(c.Scope.Include))
+	log.Info(" ", "exclude", scope.FormatRules(c.Scope.Exclude))
 	log.Info(" ", "proxy", c.ProxyURL)
+	log.Info(" ", "content dir", c.ContentDir)
+	log.Info(" ", "max body bytes", c.MaxBodyBytes)
+	log.Info(" ", "redirects", c.MaxRedirects)
+	log.Info(" ", "same host redirects", c.SameHostRedirects)
 	log.Info(" ", "retries", c.MaxRetries)
 	log.Info(" ", "rps", c.MaxRPS)
 	log.Info(" ", "timeout", c.Timeout)
 	log.Info(" ", "report", c.ReportPath)
+	log.Info(" ", "rules", c.RulesPath)
+	log.Info(" ", "warc dir", c.WARCDir)
+	log.Info(" ", "events", c.EventsPath)
+	log.Info(" ", "export", c.ExportPath)
+	log.Info(" ", "export format", c.ExportFormat)
+	log.Info(" ", "har", c.HARPath)
 }
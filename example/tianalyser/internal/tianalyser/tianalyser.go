@@ -1,12 +1,10 @@
 package tianalyser
 
 import (
-	"bytes"
 	"slices"
 	"strings"
 	"time"
 
-	"github.com/PuerkitoBio/goquery"
 	"github.com/charmbracelet/log"
 	"github.com/yusufaine/gocrawler"
 	"github.com/yusufaine/gocrawler/example/internal/filewriter"
@@ -23,8 +21,10 @@ type ReportFormat struct {
 	MaxRPS    float64 `json:"max_rps"`
 	CrawlTime string  `json:"crawl_time"`
 
-	NetInfo map[string][]gocrawler.NetworkInfo `json:"network_info"`
-	TIStats map[string][]CountryTableRow       `json:"ti_stats"`
+	NetInfo        map[string][]gocrawler.NetworkInfo `json:"network_info"`
+	RedirectChains map[string][]gocrawler.RedirectHop `json:"redirect_chains"`
+	Attempts       map[string]gocrawler.AttemptInfo   `json:"attempts"`
+	TIStats        map[string][]CountryTableRow       `json:"ti_stats"`
 }
 
 // Generates a report in JSON format from the crawler client and config. The report contains
@@ -32,11 +32,13 @@ type ReportFormat struct {
 // table for each TI page visited.
 func Generate(cr *gocrawler.Client, config *Config, elapsed time.Duration) {
 	report := ReportFormat{
-		Seed:      config.SeedURLs[0],
-		MaxRPS:    config.MaxRPS,
-		CrawlTime: elapsed.String(),
-		NetInfo:   cr.VisitedNetInfo,
-		TIStats:   make(map[string][]CountryTableRow),
+		Seed:           config.SeedURLs[0],
+		MaxRPS:         config.MaxRPS,
+		CrawlTime:      elapsed.String(),
+		NetInfo:        cr.VisitedNetInfo,
+		RedirectChains: cr.VisitedRedirects,
+		Attempts:       cr.Attempts,
+		TIStats:        make(map[string][]CountryTableRow),
 	}
 	for k, v := range report.NetInfo {
 		for i, v1 := range v {
@@ -48,22 +50,14 @@ func Generate(cr *gocrawler.Client, config *Config, elapsed time.Duration) {
 			slices.Sort(v1.VisitedPaths)
 			report.NetInfo[k][i] = v1
 
-			visitedCount := int64(len(v1.VisitedPaths))
-			if visitedCount == 0 {
-				visitedCount = 1
-			}
-			v1.AvgResponseMs = v1.TotalResponseTimeMs / visitedCount
+			v1.AvgResponseMs = v1.TotalResponseTimeMs / int64(max(v1.RequestCount, 1))
 			report.NetInfo[k][i] = v1
 		}
 	}
 
-	links := make([]string, 0, len(cr.VisitedPageInfo))
-	for k := range cr.VisitedPageInfo {
-		links = append(links, k)
-	}
-	for _, l := range links {
-		table := extractCountryRepresentationTable(cr.VisitedPageInfo[l].Content)
-		if table != nil {
+	// the tables are scraped during the crawl, see Config.ScrapeRules
+	for l, pi := range cr.VisitedPageInfo {
+		if table := countryRepresentationTable(pi.Scraped); table != nil {
 			report.TIStats[l] = table
 		}
 	}
@@ -75,44 +69,33 @@ func Generate(cr *gocrawler.Client, config *Config, elapsed time.Duration) {
 	}
 }
 
-// Uses goquery to find the table containing the country representation in
-// the response body, if any.
-func extractCountryRepresentationTable(resp []byte) []CountryTableRow {
-	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(resp))
-	if err != nil {
-		panic(err)
-	}
+// Returns the rows of the ti_stats.country_representation records scraped from a page, if any.
+// Rows without a players column are not part of the table, and the players of an empty cell
+// are nil.
+func countryRepresentationTable(scraped map[string]any) []CountryTableRow {
+	stats, _ := scraped["ti_stats"].(map[string]any)
+	records, _ := stats["country_representation"].([]any)
 
 	var repTable []CountryTableRow
-	// Table is typically found under the "Country Representation" header
-	doc.Find("#Country_Representation").
-		Parent().
-		Next().Each(func(i int, s *goquery.Selection) {
-		var row CountryTableRow
+	for _, r := range records {
+		record, _ := r.(map[string]any)
+		players, ok := record["players"].([]any)
+		if !ok {
+			continue
+		}
 
-		// Assumes that the table will only have 4 headers:
-		//	1. Row number -- ignored
-		//	2. Country/region
-		//	3. Representation
-		//	4. Players
-		s.Find("td").Each(func(i int, s *goquery.Selection) {
-			switch i % 4 {
-			case 1:
-				row.Country = strings.ReplaceAll(s.Text(), "\u00a0", "")
-			case 2:
-				row.Representation = s.Text()
-			case 3:
-				for _, pl := range strings.Split(s.Text(), ",") {
-					pl = strings.TrimSpace(pl)
-					if pl != "" {
-						row.Players = append(row.Players, pl)
-					}
-				}
-				repTable = append(repTable, row)
-				row = CountryTableRow{}
+		country, _ := record["country"].(string)
+		representation, _ := record["representation"].(string)
+		row := CountryTableRow{
+			Country:        strings.ReplaceAll(country, "\u00a0", ""),
+			Representation: representation,
+		}
+		for _, pl := range players {
+			if pl, ok := pl.(string); ok {
+				row.Players = append(row.Players, pl)
 			}
-		})
-	})
-
+		}
+		repTable = append(repTable, row)
+	}
 	return repTable
 }
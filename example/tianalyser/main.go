@@ -37,18 +37,24 @@ func main() {
 
 	start := time.Now()
 
+	// the WARC files and HAR log are closed after the report is generated
+	closeOutputs := config.Outputs.Setup("tianalyser", &config.Config)
+	defer closeOutputs()
+
 	// New crawler that skips non-OK, non-HTML responses and assumes that every TI page
 	// with a country representation links to other TI pages with country representation
 	cr := gocrawler.New(ctx,
 		&config.Config,
-		[]gocrawler.ResponseMatcher{gocrawler.IsHtmlContent},
-		tianalyser.TILinkExtractor,
+		[]gocrawler.Matcher{gocrawler.ResponseMatcher(gocrawler.IsHtmlContent)},
+		gocrawler.DefaultLinkExtractor,
 	)
 
 	// Write to file if a panic, cancellation, or completion occurs
 	defer func() {
 		log.Info("generating TI statisitcs", "file", config.ReportPath)
 		tianalyser.Generate(cr, config, time.Since(start))
+
+		config.Outputs.Write(cr, &config.Config, start)
 	}()
 
 	// Ensures that the crawler stops when the context is cancelled (ctrl-c)
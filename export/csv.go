@@ -0,0 +1,42 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// WriteCSV writes the table with a header of its column names.
+func WriteCSV(w io.Writer, t Table) error {
+	cw := csv.NewWriter(w)
+
+	record := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		record[i] = c.Name
+	}
+	if err := cw.Write(record); err != nil {
+		return err
+	}
+
+	for _, row := range t.Rows {
+		for i, v := range row {
+			switch v := v.(type) {
+			case string:
+				record[i] = v
+			case int:
+				record[i] = strconv.Itoa(v)
+			case bool:
+				record[i] = strconv.FormatBool(v)
+			default:
+				return fmt.Errorf("unsupported value %v for column %s", v, t.Columns[i].Name)
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
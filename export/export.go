@@ -0,0 +1,90 @@
+// Package export flattens the results of a crawl into tables and writes them to formats that
+// can be queried or loaded by other tools, such as SQLite, CSV and Parquet. The main text of
+// the pages is written as a text corpus during the crawl instead, see gocrawler.Config.Corpus.
+package export
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/yusufaine/gocrawler"
+)
+
+type Format string
+
+const (
+	FormatSQLite  Format = "sqlite"
+	FormatCSV     Format = "csv"
+	FormatParquet Format = "parquet"
+)
+
+// Formats lists the supported formats, in the order they are shown to users
+var Formats = []Format{FormatSQLite, FormatCSV, FormatParquet}
+
+// Tables that are written to the file-per-table formats (CSV and Parquet), SQLite has all tables
+var flatTables = []string{"pages", "links", "hosts"}
+
+// ParseFormat returns the format with the given name.
+func ParseFormat(name string) (Format, error) {
+	for _, f := range Formats {
+		if string(f) == name {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown export format %q, expected one of %v", name, Formats)
+}
+
+// Crawl describes the run that the results were collected from.
+type Crawl struct {
+	Seeds     []string
+	MaxDepth  int
+	MaxRPS    float64
+	StartedAt time.Time
+	Duration  time.Duration
+}
+
+// Write exports the results of the crawler to the path in the given format. For SQLite, the
+// path is the database file, otherwise it is the folder that the pages, links and hosts tables
+// are written to as "<table>.<format>".
+func Write(format Format, path string, crawl Crawl, cr *gocrawler.Client) error {
+	switch format {
+	case FormatSQLite:
+		_, err := WriteSQLite(path, crawl, Tables(cr))
+		return err
+	case FormatCSV:
+		return writeFiles(path, format, Tables(cr), WriteCSV)
+	case FormatParquet:
+		return writeFiles(path, format, Tables(cr), WriteParquet)
+	}
+	return fmt.Errorf("unknown export format %q", format)
+}
+
+// Writes each of the flat tables to its own file in the folder.
+func writeFiles(dir string, format Format, tables []Table, write func(io.Writer, Table) error) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	for _, t := range tables {
+		if !slices.Contains(flatTables, t.Name) {
+			continue
+		}
+
+		f, err := os.Create(filepath.Join(dir, t.Name+"."+string(format)))
+		if err != nil {
+			return err
+		}
+		if err := write(f, t); err != nil {
+			f.Close()
+			return fmt.Errorf("unable to write %s: %w", t.Name, err)
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
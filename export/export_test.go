@@ -0,0 +1,65 @@
+package export_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/yusufaine/gocrawler/export"
+)
+
+func TestWriteCSVAndParquet(t *testing.T) {
+	dir := t.TempDir()
+	for _, f := range []export.Format{export.FormatCSV, export.FormatParquet} {
+		if err := export.Write(f, dir, export.Crawl{}, newClient()); err != nil {
+			t.Fatalf("%s: %v", f, err)
+		}
+	}
+
+	csv, err := os.ReadFile(filepath.Join(dir, "pages.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(csv)), "\n")
+	expLines := []string{
+		"url,host,depth,parent,status_code,redirects,link_count,content_hash,truncated",
+		"https://a.com/,a.com,0,,200,0,2,,false",
+		"https://a.com/new,a.com,1,https://a.com/,200,1,0,,false",
+	}
+	if strings.Join(lines, "\n") != strings.Join(expLines, "\n") {
+		t.Errorf("Expected pages.csv:\n%s\ngot:\n%s", strings.Join(expLines, "\n"), csv)
+	}
+
+	// Parquet files should have the same columns and rows as the CSV files
+	for _, name := range []string{"pages", "links", "hosts"} {
+		b, err := os.ReadFile(filepath.Join(dir, name+".parquet"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		pf, err := parquet.OpenFile(bytes.NewReader(b), int64(len(b)))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		csv, err := os.ReadFile(filepath.Join(dir, name+".csv"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expRows := int64(strings.Count(string(csv), "\n") - 1)
+		if pf.NumRows() != expRows {
+			t.Errorf("Expected %d rows in %s.parquet, got %d", expRows, name, pf.NumRows())
+		}
+
+		header, _, _ := strings.Cut(string(csv), "\n")
+		cols := make([]string, 0, len(pf.Schema().Fields()))
+		for _, f := range pf.Schema().Fields() {
+			cols = append(cols, f.Name())
+		}
+		if strings.Join(cols, ",") != header {
+			t.Errorf("Expected %s.parquet columns %s, got %s", name, header, strings.Join(cols, ","))
+		}
+	}
+}
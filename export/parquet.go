@@ -0,0 +1,62 @@
+package export
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// WriteParquet writes the table as a Snappy-compressed Parquet file with a column for each of
+// the table's columns.
+func WriteParquet(w io.Writer, t Table) error {
+	group := make(parquet.Group, len(t.Columns))
+	for _, c := range t.Columns {
+		switch c.Type {
+		case String:
+			group[c.Name] = parquet.String()
+		case Int:
+			group[c.Name] = parquet.Int(64)
+		case Bool:
+			group[c.Name] = parquet.Leaf(parquet.BooleanType)
+		default:
+			return fmt.Errorf("unsupported type %s for column %s", c.Type, c.Name)
+		}
+	}
+	schema := parquet.NewSchema(t.Name, orderedGroup{Group: group, columns: t.Columns})
+
+	rows := make([]parquet.Row, 0, len(t.Rows))
+	for _, r := range t.Rows {
+		row := make(parquet.Row, len(r))
+		for i, v := range r {
+			row[i] = parquet.ValueOf(v).Level(0, 0, i)
+		}
+		rows = append(rows, row)
+	}
+
+	pw := parquet.NewWriter(w, schema, parquet.Compression(&parquet.Snappy))
+	if _, err := pw.WriteRows(rows); err != nil {
+		return err
+	}
+	return pw.Close()
+}
+
+// Group that keeps the fields in the order of the table's columns, parquet.Group orders them by
+// name, so that the columns are in the same order as the other formats.
+type orderedGroup struct {
+	parquet.Group
+	columns []Column
+}
+
+func (g orderedGroup) Fields() []parquet.Field {
+	byName := make(map[string]parquet.Field, len(g.columns))
+	for _, f := range g.Group.Fields() {
+		byName[f.Name()] = f
+	}
+
+	fields := make([]parquet.Field, len(g.columns))
+	for i, c := range g.columns {
+		fields[i] = byName[c.Name]
+	}
+	return fields
+}
@@ -0,0 +1,112 @@
+package export
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	// pure-Go SQLite driver so that the examples can be built without cgo
+	_ "modernc.org/sqlite"
+)
+
+const crawlsSchema = `CREATE TABLE IF NOT EXISTS crawls (
+	id          INTEGER PRIMARY KEY,
+	started_at  TEXT NOT NULL,
+	duration_ms INTEGER NOT NULL,
+	seeds       TEXT NOT NULL,
+	max_depth   INTEGER NOT NULL,
+	max_rps     REAL NOT NULL
+)`
+
+// WriteSQLite adds the crawl and its tables to the SQLite database at the path, creating the
+// database and tables if they do not exist. Every row is tagged with the ID of the crawl in the
+// crawls table, which is returned, so that multiple crawls can be stored and joined in one
+// database.
+func WriteSQLite(path string, crawl Crawl, tables []Table) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return 0, err
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(crawlsSchema); err != nil {
+		return 0, err
+	}
+	res, err := tx.Exec(
+		"INSERT INTO crawls (started_at, duration_ms, seeds, max_depth, max_rps) VALUES (?, ?, ?, ?, ?)",
+		crawl.StartedAt.UTC().Format(time.RFC3339),
+		crawl.Duration.Milliseconds(),
+		strings.Join(crawl.Seeds, ","),
+		crawl.MaxDepth,
+		crawl.MaxRPS,
+	)
+	if err != nil {
+		return 0, err
+	}
+	crawlID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, t := range tables {
+		if err := writeSQLiteTable(tx, crawlID, t); err != nil {
+			return 0, fmt.Errorf("unable to write %s: %w", t.Name, err)
+		}
+	}
+
+	return crawlID, tx.Commit()
+}
+
+// Creates the table and its indexes, if they do not exist, and inserts the rows.
+func writeSQLiteTable(tx *sql.Tx, crawlID int64, t Table) error {
+	cols := make([]string, 0, len(t.Columns)+1)
+	defs := make([]string, 0, len(t.Columns)+1)
+	cols = append(cols, "crawl_id")
+	defs = append(defs, "crawl_id INTEGER NOT NULL REFERENCES crawls(id)")
+	for _, c := range t.Columns {
+		cols = append(cols, c.Name)
+		defs = append(defs, c.Name+" "+string(c.Type))
+	}
+
+	stmts := []string{
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", t.Name, strings.Join(defs, ", ")),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_%s_crawl_id ON %s (crawl_id)", t.Name, t.Name),
+	}
+	for _, c := range t.Columns {
+		if c.Indexed {
+			stmts = append(stmts, fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_%s_%s ON %s (%s)", t.Name, c.Name, t.Name, c.Name))
+		}
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+
+	insert, err := tx.Prepare(fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		t.Name, strings.Join(cols, ", "), strings.TrimSuffix(strings.Repeat("?, ", len(cols)), ", ")))
+	if err != nil {
+		return err
+	}
+	defer insert.Close()
+
+	for _, row := range t.Rows {
+		if _, err := insert.Exec(append([]any{crawlID}, row...)...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package export_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yusufaine/gocrawler"
+	"github.com/yusufaine/gocrawler/export"
+)
+
+func newClient() *gocrawler.Client {
+	return &gocrawler.Client{
+		VisitedPageInfo: map[string]gocrawler.PageInfo{
+			"https://a.com/": {Depth: 0, Links: []string{"https://a.com/new", "https://b.com/x"}},
+			"https://a.com/new": {
+				Depth:     1,
+				Parent:    "https://a.com/",
+				Redirects: []gocrawler.RedirectHop{{URL: "https://a.com/old", StatusCode: 301, Location: "https://a.com/new"}},
+			},
+		},
+		Attempts: map[string]gocrawler.AttemptInfo{
+			"https://a.com/":    {Depth: 0, Outcome: gocrawler.OutcomeOK, StatusCode: 200},
+			"https://a.com/old": {Depth: 1, Outcome: gocrawler.OutcomeOK, StatusCode: 200, FinalURL: "https://a.com/new"},
+			"https://b.com/x":   {Depth: 1, Parent: "https://a.com/", Outcome: gocrawler.OutcomeHTTPError, StatusCode: 404},
+		},
+		VisitedNetInfo: map[string][]gocrawler.NetworkInfo{
+			"a.com": {{
+				RemoteIPInfo:        []gocrawler.IPInfo{{IP: "1.1.1.1", Location: "Singapore, Central", ASNumber: "AS1"}},
+				VisitedPathSet:      map[string]struct{}{"/": {}, "/new": {}},
+				RequestCount:        3,
+				TotalResponseTimeMs: 300,
+			}},
+		},
+	}
+}
+
+func TestWriteSQLite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crawls.db")
+	crawl := export.Crawl{Seeds: []string{"https://a.com/"}, MaxDepth: 1, MaxRPS: 5, StartedAt: time.Now()}
+
+	// writing twice should add a second crawl to the same tables
+	for i := int64(1); i <= 2; i++ {
+		id, err := export.WriteSQLite(path, crawl, export.Tables(newClient()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id != i {
+			t.Errorf("Expected crawl ID %d, got %d", i, id)
+		}
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var (
+		status    int
+		avgMs     int
+		broken    string
+		pageCount int
+	)
+	row := db.QueryRow(`SELECT p.status_code, t.avg_response_ms, e.url
+		FROM pages p
+		JOIN timings t ON t.crawl_id = p.crawl_id AND t.host = p.host
+		JOIN links l ON l.crawl_id = p.crawl_id AND l.source_url = p.url
+		JOIN errors e ON e.crawl_id = l.crawl_id AND e.url = l.target_url
+		WHERE p.crawl_id = 2 AND p.url = 'https://a.com/'`)
+	if err := row.Scan(&status, &avgMs, &broken); err != nil {
+		t.Fatal(err)
+	}
+	if status != 200 || avgMs != 100 || broken != "https://b.com/x" {
+		t.Errorf("Expected 200, 100, https://b.com/x, got %d, %d, %s", status, avgMs, broken)
+	}
+
+	if err := db.QueryRow("SELECT COUNT(*) FROM pages WHERE url = 'https://a.com/new' AND status_code = 200").Scan(&pageCount); err != nil {
+		t.Fatal(err)
+	}
+	if pageCount != 2 {
+		t.Errorf("Expected the redirected page in both crawls, got %d", pageCount)
+	}
+}
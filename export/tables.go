@@ -0,0 +1,213 @@
+package export
+
+import (
+	"net/url"
+	"slices"
+
+	"github.com/yusufaine/gocrawler"
+)
+
+type ColumnType string
+
+const (
+	String ColumnType = "TEXT"
+	Int    ColumnType = "INTEGER"
+	Bool   ColumnType = "BOOLEAN"
+)
+
+// Column of a table, Indexed columns are indexed by exporters that support indexes.
+type Column struct {
+	Name    string
+	Type    ColumnType
+	Indexed bool
+}
+
+// Table is a flat table of the crawl results, each row has a value for every column in order
+// with the Go type of the column (string, int or bool).
+type Table struct {
+	Name    string
+	Columns []Column
+	Rows    [][]any
+}
+
+// Tables flattens the results of the crawler into the pages, links, hosts, ips, timings and
+// errors tables. Rows are sorted so that exports of the same crawl are identical.
+func Tables(cr *gocrawler.Client) []Table {
+	return []Table{
+		pagesTable(cr),
+		linksTable(cr),
+		hostsTable(cr),
+		ipsTable(cr),
+		timingsTable(cr),
+		errorsTable(cr),
+	}
+}
+
+func pagesTable(cr *gocrawler.Client) Table {
+	t := Table{
+		Name: "pages",
+		Columns: []Column{
+			{Name: "url", Type: String, Indexed: true},
+			{Name: "host", Type: String, Indexed: true},
+			{Name: "depth", Type: Int},
+			{Name: "parent", Type: String},
+			{Name: "status_code", Type: Int},
+			{Name: "redirects", Type: Int},
+			{Name: "link_count", Type: Int},
+			{Name: "content_hash", Type: String, Indexed: true},
+			{Name: "truncated", Type: Bool},
+		},
+	}
+
+	// redirected pages are recorded under the requested URL, so look them up by the final URL
+	statuses := make(map[string]int, len(cr.Attempts))
+	for link, ai := range cr.Attempts {
+		if ai.FinalURL != "" {
+			link = ai.FinalURL
+		}
+		statuses[link] = ai.StatusCode
+	}
+
+	for _, link := range sortedKeys(cr.VisitedPageInfo) {
+		pi := cr.VisitedPageInfo[link]
+		t.Rows = append(t.Rows, []any{
+			link,
+			hostOf(link),
+			pi.Depth,
+			pi.Parent,
+			statuses[link],
+			len(pi.Redirects),
+			len(pi.Links),
+			pi.ContentHash,
+			pi.Truncated,
+		})
+	}
+	return t
+}
+
+func linksTable(cr *gocrawler.Client) Table {
+	t := Table{
+		Name: "links",
+		Columns: []Column{
+			{Name: "source_url", Type: String, Indexed: true},
+			{Name: "target_url", Type: String, Indexed: true},
+			{Name: "target_host", Type: String, Indexed: true},
+		},
+	}
+	for _, link := range sortedKeys(cr.VisitedPageInfo) {
+		for _, out := range cr.VisitedPageInfo[link].Links {
+			t.Rows = append(t.Rows, []any{link, out, hostOf(out)})
+		}
+	}
+	return t
+}
+
+func hostsTable(cr *gocrawler.Client) Table {
+	t := Table{
+		Name: "hosts",
+		Columns: []Column{
+			{Name: "host", Type: String, Indexed: true},
+			{Name: "path_count", Type: Int},
+			{Name: "ip_count", Type: Int},
+		},
+	}
+	for _, host := range sortedKeys(cr.VisitedNetInfo) {
+		for _, ni := range cr.VisitedNetInfo[host] {
+			t.Rows = append(t.Rows, []any{host, len(ni.VisitedPathSet), len(ni.RemoteIPInfo)})
+		}
+	}
+	return t
+}
+
+func ipsTable(cr *gocrawler.Client) Table {
+	t := Table{
+		Name: "ips",
+		Columns: []Column{
+			{Name: "host", Type: String, Indexed: true},
+			{Name: "ip", Type: String, Indexed: true},
+			{Name: "location", Type: String},
+			{Name: "as_number", Type: String},
+		},
+	}
+	for _, host := range sortedKeys(cr.VisitedNetInfo) {
+		for _, ni := range cr.VisitedNetInfo[host] {
+			for _, ip := range ni.RemoteIPInfo {
+				t.Rows = append(t.Rows, []any{host, ip.IP, ip.Location, ip.ASNumber})
+			}
+		}
+	}
+	return t
+}
+
+func timingsTable(cr *gocrawler.Client) Table {
+	t := Table{
+		Name: "timings",
+		Columns: []Column{
+			{Name: "host", Type: String, Indexed: true},
+			{Name: "requests", Type: Int},
+			{Name: "total_response_ms", Type: Int},
+			{Name: "avg_response_ms", Type: Int},
+		},
+	}
+	for _, host := range sortedKeys(cr.VisitedNetInfo) {
+		for _, ni := range cr.VisitedNetInfo[host] {
+			t.Rows = append(t.Rows, []any{
+				host,
+				ni.RequestCount,
+				int(ni.TotalResponseTimeMs),
+				int(ni.TotalResponseTimeMs) / max(ni.RequestCount, 1),
+			})
+		}
+	}
+	return t
+}
+
+func errorsTable(cr *gocrawler.Client) Table {
+	t := Table{
+		Name: "errors",
+		Columns: []Column{
+			{Name: "url", Type: String, Indexed: true},
+			{Name: "depth", Type: Int},
+			{Name: "parent", Type: String},
+			{Name: "outcome", Type: String, Indexed: true},
+			{Name: "status_code", Type: Int},
+			{Name: "error_class", Type: String},
+			{Name: "error", Type: String},
+			{Name: "final_url", Type: String},
+		},
+	}
+	for _, link := range sortedKeys(cr.Attempts) {
+		ai := cr.Attempts[link]
+		if !ai.Outcome.IsError() {
+			continue
+		}
+		t.Rows = append(t.Rows, []any{
+			link,
+			ai.Depth,
+			ai.Parent,
+			string(ai.Outcome),
+			ai.StatusCode,
+			ai.ErrorClass,
+			ai.Error,
+			ai.FinalURL,
+		})
+	}
+	return t
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func hostOf(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
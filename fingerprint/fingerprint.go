@@ -0,0 +1,95 @@
+// Package fingerprint computes fingerprints of page content that can be compared to find pages
+// that are near-duplicates of each other, such as pages that only differ by a session ID, date
+// or the order of a few items.
+package fingerprint
+
+import (
+	"bytes"
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+// DefaultDistance is the max number of bits that the SimHash of two pages can differ by for
+// the pages to be considered near-duplicates.
+const DefaultDistance = 3
+
+// number of consecutive words hashed together, so that the order of the words matters
+const shingleSize = 3
+
+// Text returns the lowercased words of the visible text of the HTML, tags and the contents of
+// script, style, noscript and template tags are ignored.
+func Text(body []byte) []string {
+	var (
+		words []string
+		skip  int
+	)
+	z := html.NewTokenizer(bytes.NewReader(body))
+	for tt := z.Next(); tt != html.ErrorToken; tt = z.Next() {
+		switch tt {
+		case html.StartTagToken, html.EndTagToken:
+			name, _ := z.TagName()
+			switch string(name) {
+			case "script", "style", "noscript", "template":
+				if tt == html.StartTagToken {
+					skip++
+				} else if skip > 0 {
+					skip--
+				}
+			}
+		case html.TextToken:
+			if skip > 0 {
+				continue
+			}
+			words = append(words, strings.FieldsFunc(strings.ToLower(string(z.Text())), func(r rune) bool {
+				return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+			})...)
+		}
+	}
+	return words
+}
+
+// SimHash returns the 64-bit SimHash of the words, computed over shingles of 3 consecutive
+// words. Similar texts have hashes that differ by only a few bits, see Distance. The SimHash
+// of no words is 0.
+func SimHash(words []string) uint64 {
+	if len(words) == 0 {
+		return 0
+	}
+
+	var weights [64]int
+	add := func(shingle []string) {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(shingle, " ")))
+		sum := h.Sum64()
+		for i := range weights {
+			if sum&(1<<i) != 0 {
+				weights[i]++
+			} else {
+				weights[i]--
+			}
+		}
+	}
+	if len(words) < shingleSize {
+		add(words)
+	}
+	for i := 0; i+shingleSize <= len(words); i++ {
+		add(words[i : i+shingleSize])
+	}
+
+	var simhash uint64
+	for i, w := range weights {
+		if w > 0 {
+			simhash |= 1 << i
+		}
+	}
+	return simhash
+}
+
+// Distance returns the number of bits that differ between two SimHashes.
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
@@ -0,0 +1,97 @@
+package fingerprint_test
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/yusufaine/gocrawler/fingerprint"
+)
+
+func TestText(t *testing.T) {
+	body := `<html><head><title>Hello</title><style>p { color: red; }</style></head>
+<body><p>Hello, <b>World</b>!</p><script>var x = "<p>no</p>";</script><p>it's 2023</p></body></html>`
+
+	exp := []string{"hello", "hello", "world", "it", "s", "2023"}
+	if got := fingerprint.Text([]byte(body)); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %v, got %v", exp, got)
+	}
+}
+
+func TestSimHash(t *testing.T) {
+	var items []string
+	for i := 0; i < 100; i++ {
+		items = append(items, fmt.Sprintf("<li>item number %d of the calendar</li>", i))
+	}
+	page := func(session string) []byte {
+		return []byte("<ul>" + strings.Join(items, "") + "</ul><a href=\"?sid=" + session + "\">next " + session + "</a>")
+	}
+
+	a := fingerprint.SimHash(fingerprint.Text(page("abc123")))
+	b := fingerprint.SimHash(fingerprint.Text(page("def456")))
+	if d := fingerprint.Distance(a, b); d > fingerprint.DefaultDistance {
+		t.Errorf("Expected pages differing by a session ID to be near-duplicates, got distance %d", d)
+	}
+
+	other := fingerprint.SimHash(fingerprint.Text([]byte("<p>a completely different page about the weather in the city today</p>")))
+	if d := fingerprint.Distance(a, other); d <= fingerprint.DefaultDistance {
+		t.Errorf("Expected different pages not to be near-duplicates, got distance %d", d)
+	}
+
+	if fingerprint.SimHash(nil) != 0 {
+		t.Errorf("Expected the SimHash of no words to be 0")
+	}
+}
+
+func TestIndex(t *testing.T) {
+	rng := rand.New(rand.NewSource(3230))
+	hashes := make(map[string]uint64)
+	for i := 0; i < 500; i++ {
+		hashes[fmt.Sprintf("page-%03d", i)] = rng.Uint64()
+	}
+	// near-duplicates of a few pages, the last query is equally close to page-003 and tie
+	hashes["near-a"] = hashes["page-001"] ^ 0b101
+	hashes["near-b"] = hashes["page-002"] ^ 1<<63
+	hashes["tie"] = hashes["page-003"] ^ 0b11
+
+	for _, maxDistance := range []int{0, 1, 3, 10} {
+		ix := fingerprint.NewIndex(maxDistance)
+		for key, simhash := range hashes {
+			ix.Add(key, simhash)
+		}
+
+		for _, q := range []uint64{hashes["page-001"] ^ 0b100, hashes["page-002"], rng.Uint64(), hashes["page-003"] ^ 0b01} {
+			// compare with every hash to find the expected result
+			var (
+				expKey   string
+				expDist  int
+				expFound bool
+			)
+			for key, simhash := range hashes {
+				d := fingerprint.Distance(q, simhash)
+				if d <= maxDistance && (!expFound || d < expDist || (d == expDist && key < expKey)) {
+					expKey, expDist, expFound = key, d, true
+				}
+			}
+
+			key, dist, found := ix.Nearest(q)
+			if key != expKey || dist != expDist || found != expFound {
+				t.Errorf("max distance %d: expected %q, %d, %v, got %q, %d, %v", maxDistance, expKey, expDist, expFound, key, dist, found)
+			}
+
+			var expWithin []string
+			for key, simhash := range hashes {
+				if fingerprint.Distance(q, simhash) <= maxDistance {
+					expWithin = append(expWithin, key)
+				}
+			}
+			slices.Sort(expWithin)
+			if within := ix.Within(q); !reflect.DeepEqual(within, expWithin) {
+				t.Errorf("max distance %d: expected %v within, got %v", maxDistance, expWithin, within)
+			}
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package fingerprint
+
+import "slices"
+
+// Index finds the SimHashes added to it that are within a max distance of another SimHash
+// without comparing it with every SimHash. The 64 bits are split into maxDistance+1 bands, and
+// two SimHashes that differ by at most maxDistance bits have at least one identical band, so only
+// the SimHashes that share a band are compared. It is not safe for concurrent use.
+type Index struct {
+	maxDistance int
+	bands       []band
+	tables      []map[uint64][]int // per band, the entries whose band has the value
+	entries     []entry
+}
+
+type band struct {
+	shift uint
+	mask  uint64
+}
+
+type entry struct {
+	key     string
+	simhash uint64
+}
+
+// NewIndex returns an index of SimHashes that differ by at most maxDistance bits, which must
+// be between 0 and 63.
+func NewIndex(maxDistance int) *Index {
+	maxDistance = min(max(maxDistance, 0), 63)
+	n := maxDistance + 1
+	ix := &Index{
+		maxDistance: maxDistance,
+		bands:       make([]band, n),
+		tables:      make([]map[uint64][]int, n),
+	}
+	// the first bands take the remaining bits if 64 is not divisible by the number of bands
+	shift := 0
+	for i := range ix.bands {
+		width := 64 / n
+		if i < 64%n {
+			width++
+		}
+		ix.bands[i] = band{shift: uint(shift), mask: 1<<width - 1}
+		ix.tables[i] = make(map[uint64][]int)
+		shift += width
+	}
+	return ix
+}
+
+// Add adds the SimHash under the key.
+func (ix *Index) Add(key string, simhash uint64) {
+	id := len(ix.entries)
+	ix.entries = append(ix.entries, entry{key: key, simhash: simhash})
+	for i, b := range ix.bands {
+		v := simhash >> b.shift & b.mask
+		ix.tables[i][v] = append(ix.tables[i][v], id)
+	}
+}
+
+// Nearest returns the key of the closest SimHash within the max distance and its distance, ties
+// are broken by the key so that the result does not depend on the order the SimHashes were added.
+func (ix *Index) Nearest(simhash uint64) (string, int, bool) {
+	var (
+		nearest string
+		dist    int
+		found   bool
+	)
+	for i, b := range ix.bands {
+		for _, id := range ix.tables[i][simhash>>b.shift&b.mask] {
+			e := ix.entries[id]
+			d := Distance(simhash, e.simhash)
+			if d > ix.maxDistance {
+				continue
+			}
+			if !found || d < dist || (d == dist && e.key < nearest) {
+				nearest, dist, found = e.key, d, true
+			}
+		}
+	}
+	return nearest, dist, found
+}
+
+// Within returns the keys of all the SimHashes within the max distance, sorted.
+func (ix *Index) Within(simhash uint64) []string {
+	seen := make(map[int]bool)
+	var keys []string
+	for i, b := range ix.bands {
+		for _, id := range ix.tables[i][simhash>>b.shift&b.mask] {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			if e := ix.entries[id]; Distance(simhash, e.simhash) <= ix.maxDistance {
+				keys = append(keys, e.key)
+			}
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
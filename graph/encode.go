@@ -0,0 +1,286 @@
+package graph
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type Format string
+
+const (
+	FormatGraphML Format = "graphml"
+	FormatDOT     Format = "dot"
+	FormatGEXF    Format = "gexf"
+)
+
+// FormatFromPath returns the format of the file from its extension, which is one of ".graphml",
+// ".dot", ".gv" or ".gexf".
+func FormatFromPath(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".graphml":
+		return FormatGraphML, nil
+	case ".dot", ".gv":
+		return FormatDOT, nil
+	case ".gexf":
+		return FormatGEXF, nil
+	}
+	return "", fmt.Errorf("unknown graph format for %q, expected .graphml, .dot, .gv or .gexf", path)
+}
+
+// Encode writes the graph in the given format.
+func (g *Graph) Encode(w io.Writer, f Format) error {
+	switch f {
+	case FormatGraphML:
+		return g.encodeGraphML(w)
+	case FormatDOT:
+		return g.encodeDOT(w)
+	case FormatGEXF:
+		return g.encodeGEXF(w)
+	}
+	return fmt.Errorf("unknown graph format %q", f)
+}
+
+// Attributes of nodes and edges, in the order that they are declared
+var (
+	nodeAttrs = []attrDef{
+		{"host", "string"},
+		{"depth", "int"},
+		{"status", "int"},
+		{"outcome", "string"},
+		{"country", "string"},
+		{"pages", "int"},
+	}
+	edgeAttrs = []attrDef{
+		{"count", "int"},
+		{"anchors", "string"},
+	}
+)
+
+type attrDef struct {
+	name string
+	typ  string
+}
+
+// Returns the attributes of the node that are set, zero values are omitted except for depth 0.
+func (n Node) attrs() [][2]string {
+	var attrs [][2]string
+	add := func(name, val string) {
+		if val != "" {
+			attrs = append(attrs, [2]string{name, val})
+		}
+	}
+	add("host", n.Host)
+	if n.Depth >= 0 {
+		add("depth", strconv.Itoa(n.Depth))
+	}
+	if n.Status > 0 {
+		add("status", strconv.Itoa(n.Status))
+	}
+	add("outcome", string(n.Outcome))
+	add("country", n.Country)
+	if n.Pages > 0 {
+		add("pages", strconv.Itoa(n.Pages))
+	}
+	return attrs
+}
+
+func (e Edge) attrs() [][2]string {
+	attrs := [][2]string{{"count", strconv.Itoa(e.Count)}}
+	if len(e.Anchors) > 0 {
+		attrs = append(attrs, [2]string{"anchors", strings.Join(e.Anchors, " | ")})
+	}
+	return attrs
+}
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   struct {
+		ID          string        `xml:"id,attr"`
+		EdgeDefault string        `xml:"edgedefault,attr"`
+		Nodes       []graphMLElem `xml:"node"`
+		Edges       []graphMLElem `xml:"edge"`
+	} `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLElem struct {
+	ID     string        `xml:"id,attr,omitempty"`
+	Source string        `xml:"source,attr,omitempty"`
+	Target string        `xml:"target,attr,omitempty"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+func (g *Graph) encodeGraphML(w io.Writer) error {
+	doc := graphML{XMLNS: "http://graphml.graphdrawing.org/xmlns"}
+	doc.Graph.ID = "crawl"
+	doc.Graph.EdgeDefault = "directed"
+	for _, a := range nodeAttrs {
+		doc.Keys = append(doc.Keys, graphMLKey{ID: "n_" + a.name, For: "node", Name: a.name, Type: a.typ})
+	}
+	for _, a := range edgeAttrs {
+		doc.Keys = append(doc.Keys, graphMLKey{ID: "e_" + a.name, For: "edge", Name: a.name, Type: a.typ})
+	}
+
+	for _, n := range g.Nodes {
+		el := graphMLElem{ID: n.ID}
+		for _, a := range n.attrs() {
+			el.Data = append(el.Data, graphMLData{Key: "n_" + a[0], Value: a[1]})
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, el)
+	}
+	for _, e := range g.Edges {
+		el := graphMLElem{Source: e.Source, Target: e.Target}
+		for _, a := range e.attrs() {
+			el.Data = append(el.Data, graphMLData{Key: "e_" + a[0], Value: a[1]})
+		}
+		doc.Graph.Edges = append(doc.Graph.Edges, el)
+	}
+
+	return encodeXML(w, doc)
+}
+
+type gexf struct {
+	XMLName xml.Name `xml:"gexf"`
+	XMLNS   string   `xml:"xmlns,attr"`
+	Version string   `xml:"version,attr"`
+	Creator string   `xml:"meta>creator"`
+	Graph   struct {
+		DefaultEdgeType string           `xml:"defaultedgetype,attr"`
+		Attributes      []gexfAttributes `xml:"attributes"`
+		Nodes           []gexfElem       `xml:"nodes>node"`
+		Edges           []gexfElem       `xml:"edges>edge"`
+	} `xml:"graph"`
+}
+
+type gexfAttributes struct {
+	Class string          `xml:"class,attr"`
+	Attrs []gexfAttribute `xml:"attribute"`
+}
+
+type gexfAttribute struct {
+	ID    string `xml:"id,attr"`
+	Title string `xml:"title,attr"`
+	Type  string `xml:"type,attr"`
+}
+
+type gexfElem struct {
+	ID        string         `xml:"id,attr"`
+	Label     string         `xml:"label,attr,omitempty"`
+	Source    string         `xml:"source,attr,omitempty"`
+	Target    string         `xml:"target,attr,omitempty"`
+	Weight    int            `xml:"weight,attr,omitempty"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue"`
+}
+
+type gexfAttValue struct {
+	For   string `xml:"for,attr"`
+	Value string `xml:"value,attr"`
+}
+
+func newGEXFAttributes(class string, defs []attrDef) gexfAttributes {
+	attrs := gexfAttributes{Class: class}
+	for _, a := range defs {
+		typ := a.typ
+		if typ == "int" {
+			typ = "integer"
+		}
+		attrs.Attrs = append(attrs.Attrs, gexfAttribute{ID: a.name, Title: a.name, Type: typ})
+	}
+	return attrs
+}
+
+func (g *Graph) encodeGEXF(w io.Writer) error {
+	doc := gexf{
+		XMLNS:   "http://gexf.net/1.3",
+		Version: "1.3",
+		Creator: "gocrawler",
+	}
+	doc.Graph.DefaultEdgeType = "directed"
+	doc.Graph.Attributes = []gexfAttributes{
+		newGEXFAttributes("node", nodeAttrs),
+		newGEXFAttributes("edge", edgeAttrs),
+	}
+
+	withValues := func(el gexfElem, attrs [][2]string) gexfElem {
+		for _, a := range attrs {
+			el.AttValues = append(el.AttValues, gexfAttValue{For: a[0], Value: a[1]})
+		}
+		return el
+	}
+	for _, n := range g.Nodes {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, withValues(gexfElem{ID: n.ID, Label: n.ID}, n.attrs()))
+	}
+	for i, e := range g.Edges {
+		el := gexfElem{ID: strconv.Itoa(i), Source: e.Source, Target: e.Target, Weight: e.Count}
+		doc.Graph.Edges = append(doc.Graph.Edges, withValues(el, e.attrs()))
+	}
+
+	return encodeXML(w, doc)
+}
+
+func encodeXML(w io.Writer, doc any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// Writes the graph in the Graphviz DOT language, the attributes are kept as custom attributes
+// and the count is used as the edge weight.
+func (g *Graph) encodeDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph crawl {")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(bw, "  %s [%s];\n", dotQuote(n.ID), dotAttrs(n.attrs()))
+	}
+	for _, e := range g.Edges {
+		attrs := e.attrs()
+		attrs = append(attrs, [2]string{"weight", strconv.Itoa(e.Count)})
+		if len(e.Anchors) > 0 {
+			attrs = append(attrs, [2]string{"label", strings.Join(e.Anchors, " | ")})
+		}
+		fmt.Fprintf(bw, "  %s -> %s [%s];\n", dotQuote(e.Source), dotQuote(e.Target), dotAttrs(attrs))
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+func dotAttrs(attrs [][2]string) string {
+	parts := make([]string, 0, len(attrs))
+	for _, a := range attrs {
+		parts = append(parts, a[0]+"="+dotQuote(a[1]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Quotes the string as a DOT ID, only double quotes and backslashes need to be escaped.
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
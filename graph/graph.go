@@ -0,0 +1,169 @@
+// Package graph builds the link graph of a crawl at the page or host level so that it can be
+// exported to GraphML, Graphviz DOT or GEXF and visualised in tools such as Gephi and Graphviz.
+package graph
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/yusufaine/gocrawler"
+)
+
+// Node is a page or a host of the crawl. Status and Outcome are only set for pages, and Pages is
+// only set for hosts.
+type Node struct {
+	ID      string
+	Host    string
+	Depth   int
+	Status  int
+	Outcome gocrawler.Outcome
+	Country string
+	Pages   int
+}
+
+// Edge is a link from one node to another. Count is the number of links between the two nodes,
+// and Anchors are the distinct anchor texts of the links, which are only known for HTML pages.
+type Edge struct {
+	Source  string
+	Target  string
+	Count   int
+	Anchors []string
+}
+
+// Graph is a directed graph with its nodes sorted by ID and edges sorted by source and target.
+type Graph struct {
+	Nodes []Node
+	Edges []Edge
+}
+
+// PageGraph builds the graph of pages visited by the crawler and the links between them. Links
+// that were redirected point to the page they were redirected to, and links that were not
+// visited (e.g. broken or filtered links) are included with the outcome of the attempt.
+func PageGraph(cr *gocrawler.Client) *Graph {
+	// redirected links are recorded under the requested URL, so look them up by the final URL
+	finalAttempts := make(map[string]gocrawler.AttemptInfo, len(cr.Attempts))
+	for link, ai := range cr.Attempts {
+		if ai.FinalURL != "" {
+			finalAttempts[ai.FinalURL] = ai
+		} else if _, ok := finalAttempts[link]; !ok {
+			finalAttempts[link] = ai
+		}
+	}
+	resolve := func(link string) string {
+		if ai, ok := cr.Attempts[link]; ok && ai.FinalURL != "" {
+			return ai.FinalURL
+		}
+		return link
+	}
+
+	nodes := make(map[string]Node)
+	addNode := func(link string) {
+		if _, ok := nodes[link]; ok {
+			return
+		}
+		n := Node{ID: link, Depth: -1}
+		if u, err := url.Parse(link); err == nil {
+			n.Host = u.Host
+			n.Country = country(cr, u.Host)
+		}
+		if ai, ok := finalAttempts[link]; ok {
+			n.Depth = ai.Depth
+			n.Status = ai.StatusCode
+			n.Outcome = ai.Outcome
+		}
+		if pi, ok := cr.VisitedPageInfo[link]; ok {
+			n.Depth = pi.Depth
+		}
+		nodes[link] = n
+	}
+
+	edges := make(map[[2]string]*Edge)
+	for link, pi := range cr.VisitedPageInfo {
+		addNode(link)
+
+		for _, out := range pi.Links {
+			target := resolve(out)
+			addNode(target)
+
+			e, ok := edges[[2]string{link, target}]
+			if !ok {
+				e = &Edge{Source: link, Target: target}
+				edges[[2]string{link, target}] = e
+			}
+			e.Count += max(pi.Anchors[out].Count, 1)
+			for _, text := range pi.Anchors[out].Texts {
+				if !slices.Contains(e.Anchors, text) {
+					e.Anchors = append(e.Anchors, text)
+				}
+			}
+		}
+	}
+
+	return newGraph(nodes, edges)
+}
+
+// HostGraph groups the nodes of the page graph by host, summing the links between pages of each
+// host. A host's depth is the lowest depth of its pages.
+func HostGraph(pg *Graph) *Graph {
+	nodes := make(map[string]Node)
+	hostOf := make(map[string]string, len(pg.Nodes))
+	for _, pn := range pg.Nodes {
+		hostOf[pn.ID] = pn.Host
+		n, ok := nodes[pn.Host]
+		if !ok {
+			n = Node{ID: pn.Host, Host: pn.Host, Depth: pn.Depth, Country: pn.Country}
+		}
+		if n.Depth < 0 || (pn.Depth >= 0 && pn.Depth < n.Depth) {
+			n.Depth = pn.Depth
+		}
+		n.Pages++
+		nodes[pn.Host] = n
+	}
+
+	edges := make(map[[2]string]*Edge)
+	for _, pe := range pg.Edges {
+		key := [2]string{hostOf[pe.Source], hostOf[pe.Target]}
+		e, ok := edges[key]
+		if !ok {
+			e = &Edge{Source: key[0], Target: key[1]}
+			edges[key] = e
+		}
+		e.Count += pe.Count
+	}
+
+	return newGraph(nodes, edges)
+}
+
+func newGraph(nodes map[string]Node, edges map[[2]string]*Edge) *Graph {
+	g := &Graph{
+		Nodes: make([]Node, 0, len(nodes)),
+		Edges: make([]Edge, 0, len(edges)),
+	}
+	for _, n := range nodes {
+		g.Nodes = append(g.Nodes, n)
+	}
+	for _, e := range edges {
+		g.Edges = append(g.Edges, *e)
+	}
+	slices.SortFunc(g.Nodes, func(a, b Node) int { return strings.Compare(a.ID, b.ID) })
+	slices.SortFunc(g.Edges, func(a, b Edge) int {
+		if c := strings.Compare(a.Source, b.Source); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Target, b.Target)
+	})
+	return g
+}
+
+// Returns the country of the first IP address of the host, locations are "<country>, <region>".
+func country(cr *gocrawler.Client, host string) string {
+	for _, ni := range cr.VisitedNetInfo[host] {
+		for _, ip := range ni.RemoteIPInfo {
+			if c, _, _ := strings.Cut(ip.Location, ", "); c != "" {
+				return c
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,107 @@
+package graph_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yusufaine/gocrawler"
+	"github.com/yusufaine/gocrawler/graph"
+)
+
+func newClient(t *testing.T) *gocrawler.Client {
+	t.Helper()
+	return &gocrawler.Client{
+		VisitedPageInfo: map[string]gocrawler.PageInfo{
+			"https://a.com/": {
+				Depth: 0,
+				Links: []string{"https://a.com/b", "https://a.com/old", "https://b.com/x"},
+				Anchors: map[string]gocrawler.LinkAnchors{
+					"https://a.com/b":   {Count: 2, Texts: []string{"B page", "top"}},
+					"https://a.com/old": {Count: 1, Texts: []string{"old"}},
+				},
+			},
+			"https://a.com/b": {Depth: 1, Parent: "https://a.com/"},
+			"https://a.com/new": {
+				Depth:  1,
+				Parent: "https://a.com/",
+				Links:  []string{"https://b.com/x"},
+			},
+		},
+		Attempts: map[string]gocrawler.AttemptInfo{
+			"https://a.com/":    {Depth: 0, Outcome: gocrawler.OutcomeOK, StatusCode: 200},
+			"https://a.com/b":   {Depth: 1, Outcome: gocrawler.OutcomeOK, StatusCode: 200},
+			"https://a.com/old": {Depth: 1, Outcome: gocrawler.OutcomeOK, StatusCode: 200, FinalURL: "https://a.com/new"},
+			"https://b.com/x":   {Depth: 1, Outcome: gocrawler.OutcomeHTTPError, StatusCode: 404},
+		},
+		VisitedNetInfo: map[string][]gocrawler.NetworkInfo{
+			"a.com": {{RemoteIPInfo: []gocrawler.IPInfo{{IP: "1.1.1.1", Location: "Singapore, Central"}}}},
+		},
+	}
+}
+
+func TestPageGraph(t *testing.T) {
+	g := graph.PageGraph(newClient(t))
+
+	expNodes := []graph.Node{
+		{ID: "https://a.com/", Host: "a.com", Depth: 0, Status: 200, Outcome: gocrawler.OutcomeOK, Country: "Singapore"},
+		{ID: "https://a.com/b", Host: "a.com", Depth: 1, Status: 200, Outcome: gocrawler.OutcomeOK, Country: "Singapore"},
+		{ID: "https://a.com/new", Host: "a.com", Depth: 1, Status: 200, Outcome: gocrawler.OutcomeOK, Country: "Singapore"},
+		{ID: "https://b.com/x", Host: "b.com", Depth: 1, Status: 404, Outcome: gocrawler.OutcomeHTTPError},
+	}
+	if !reflect.DeepEqual(g.Nodes, expNodes) {
+		t.Errorf("Expected nodes %+v, got %+v", expNodes, g.Nodes)
+	}
+
+	// redirected links point to the final page, and links without anchors are counted once
+	expEdges := []graph.Edge{
+		{Source: "https://a.com/", Target: "https://a.com/b", Count: 2, Anchors: []string{"B page", "top"}},
+		{Source: "https://a.com/", Target: "https://a.com/new", Count: 1, Anchors: []string{"old"}},
+		{Source: "https://a.com/", Target: "https://b.com/x", Count: 1},
+		{Source: "https://a.com/new", Target: "https://b.com/x", Count: 1},
+	}
+	if !reflect.DeepEqual(g.Edges, expEdges) {
+		t.Errorf("Expected edges %+v, got %+v", expEdges, g.Edges)
+	}
+
+	hg := graph.HostGraph(g)
+	expHostEdges := []graph.Edge{
+		{Source: "a.com", Target: "a.com", Count: 3},
+		{Source: "a.com", Target: "b.com", Count: 2},
+	}
+	if !reflect.DeepEqual(hg.Edges, expHostEdges) {
+		t.Errorf("Expected host edges %+v, got %+v", expHostEdges, hg.Edges)
+	}
+	if hg.Nodes[0].Pages != 3 {
+		t.Errorf("Expected 3 pages for a.com, got %d", hg.Nodes[0].Pages)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	g := graph.PageGraph(newClient(t))
+	for _, f := range []graph.Format{graph.FormatGraphML, graph.FormatGEXF, graph.FormatDOT} {
+		var buf bytes.Buffer
+		if err := g.Encode(&buf, f); err != nil {
+			t.Fatalf("%s: %v", f, err)
+		}
+
+		if f == graph.FormatDOT {
+			exp := `"https://a.com/" -> "https://a.com/b" [count="2", anchors="B page | top", weight="2", label="B page | top"];`
+			if !strings.Contains(buf.String(), exp) {
+				t.Errorf("Expected DOT to contain %s, got:\n%s", exp, buf.String())
+			}
+			continue
+		}
+		dec := xml.NewDecoder(&buf)
+		for {
+			if _, err := dec.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s is not well-formed XML: %v", f, err)
+			}
+		}
+	}
+}
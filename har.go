@@ -0,0 +1,14 @@
+package gocrawler
+
+import "github.com/yusufaine/gocrawler/internal/rhttp"
+
+// HARRecorder records every HTTP request made by the crawler, including retries, redirects and
+// IP lookups, as an HTTP Archive (HAR) 1.2 log that can be loaded into browser devtools. Use
+// HAR() to get the log once crawling is done, e.g. to write it to a JSON file.
+type HARRecorder = rhttp.HARRecorder
+
+// NewHARRecorder creates a HARRecorder to be set in Config. Response bodies, as read by the
+// crawler, are only included if withBodies is set as they are kept in memory until the end.
+func NewHARRecorder(withBodies bool) *HARRecorder {
+	return rhttp.NewHARRecorder(withBodies)
+}
@@ -0,0 +1,177 @@
+// Package hostrule matches hosts against rules for a host, its subdomains, its registrable
+// domain or a glob, so that host blacklists, allowlists and scope rules share the same syntax.
+package hostrule
+
+import (
+	"fmt"
+	"net"
+	"path"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// Kind is how a Rule matches hosts.
+type Kind int
+
+const (
+	Subdomains Kind = iota // the host and its subdomains, and the host without its www. prefix
+	Exact                  // only the host itself, e.g. t.co does not match www.t.co
+	Domain                 // every host with the same registrable domain (eTLD+1)
+	Glob                   // hosts that match the pattern with path.Match, e.g. *.cdn.*
+)
+
+// Rule matches hosts by name, ignoring the port and case.
+type Rule struct {
+	Host string
+	Kind Kind
+}
+
+// Parse parses "example.com", ".example.com" or "*.example.com" as a rule for the host and its
+// subdomains, "exact:example.com" for the host only, "domain:www.example.co.uk" for every host
+// of the registrable domain according to the public suffix list, example.co.uk in this case, and
+// any other pattern with a * as a glob (e.g. "*.cdn.*"). Rules for a host that starts with www.
+// also match the host without it, so www.example.com matches example.com. The port of the host,
+// if any, is ignored.
+func Parse(s string) (Rule, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	var r Rule
+	switch {
+	case strings.HasPrefix(s, "domain:"):
+		r = Rule{Host: strings.TrimPrefix(s, "domain:"), Kind: Domain}
+	case strings.HasPrefix(s, "exact:"):
+		r = Rule{Host: strings.TrimPrefix(s, "exact:"), Kind: Exact}
+	case strings.HasPrefix(s, "*.") && !strings.Contains(s[2:], "*"):
+		r = Rule{Host: s[2:], Kind: Subdomains}
+	case strings.Contains(s, "*"):
+		if _, err := path.Match(s, ""); err != nil || strings.ContainsAny(s, "/: ") {
+			return Rule{}, fmt.Errorf("invalid host rule %q", s)
+		}
+		return Rule{Host: s, Kind: Glob}, nil
+	case strings.HasPrefix(s, "."):
+		r = Rule{Host: s[1:], Kind: Subdomains}
+	default:
+		r = Rule{Host: s, Kind: Subdomains}
+	}
+	r.Host = normaliseHost(r.Host)
+	if r.Host == "" || (net.ParseIP(r.Host) == nil && strings.ContainsAny(r.Host, "/*:[] ")) {
+		return r, fmt.Errorf("invalid host rule %q", s)
+	}
+	return r, nil
+}
+
+// Matches reports whether the host, with or without a port, matches the rule. Domain rules for
+// hosts without a registrable domain, such as IP addresses and public suffixes, match the host
+// only.
+func (r Rule) Matches(host string) bool {
+	host = normaliseHost(host)
+	ruleHost := normaliseHost(r.Host)
+	switch r.Kind {
+	case Subdomains:
+		if bare, ok := strings.CutPrefix(ruleHost, "www."); ok && host == bare {
+			return true
+		}
+		return host == ruleHost || strings.HasSuffix(host, "."+ruleHost)
+	case Domain:
+		domain, ok := registrableDomain(ruleHost)
+		if !ok {
+			return host == ruleHost
+		}
+		return host == domain || strings.HasSuffix(host, "."+domain)
+	case Glob:
+		ok, _ := path.Match(r.Host, host)
+		return ok
+	default:
+		return host == ruleHost
+	}
+}
+
+// String returns the rule in the format parsed by Parse.
+func (r Rule) String() string {
+	switch r.Kind {
+	case Exact:
+		return "exact:" + r.Host
+	case Domain:
+		return "domain:" + r.Host
+	default:
+		return r.Host
+	}
+}
+
+// Specificity is how specific the rule is, a rule for a host is more specific than a rule for
+// its subdomains, which is more specific than a rule for its registrable domain, and globs are
+// the least specific. Rules of the same kind are more specific the longer the host is.
+func (r Rule) Specificity() int {
+	var rank int
+	switch r.Kind {
+	case Exact:
+		rank = 3
+	case Subdomains:
+		rank = 2
+	case Domain:
+		rank = 1
+	}
+	return rank<<16 + len(r.Host)
+}
+
+// Rules matches hosts against any of the rules.
+type Rules []Rule
+
+// ParseRules parses a comma-separated list of rules with Parse, ignoring empty entries.
+func ParseRules(s string) (Rules, error) {
+	var rules Rules
+	for _, entry := range strings.Split(s, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		r, err := Parse(entry)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// Match returns the most specific rule that matches the host, if any.
+func (rs Rules) Match(host string) (Rule, bool) {
+	var (
+		best  Rule
+		found bool
+	)
+	for _, r := range rs {
+		if r.Matches(host) && (!found || r.Specificity() > best.Specificity()) {
+			best, found = r, true
+		}
+	}
+	return best, found
+}
+
+// Strings returns the rules in the format parsed by Parse.
+func (rs Rules) Strings() []string {
+	s := make([]string, len(rs))
+	for i, r := range rs {
+		s[i] = r.String()
+	}
+	return s
+}
+
+func normaliseHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+// Returns the eTLD+1 of the host, e.g. example.co.uk for www.example.co.uk.
+func registrableDomain(host string) (string, bool) {
+	if net.ParseIP(host) != nil {
+		return "", false
+	}
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return "", false
+	}
+	return domain, true
+}
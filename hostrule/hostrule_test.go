@@ -0,0 +1,99 @@
+package hostrule_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yusufaine/gocrawler/hostrule"
+)
+
+func TestRuleMatches(t *testing.T) {
+	tests := []struct {
+		rule string
+		host string
+		exp  bool
+	}{
+		// matched by name, not by substring
+		{"t.co", "t.co", true},
+		{"t.co", "www.t.co", true},
+		{"t.co", "microsoft.com", false},
+		{"t.co", "t.com", false},
+		{"exact:t.co", "www.t.co", false},
+		{"exact:t.co", "T.CO:443", true},
+		{"www.youtube.com", "youtube.com", true},
+		{"www.youtube.com", "m.youtube.com", false},
+		{"*.youtube.com", "youtube.com", true},
+		{".youtube.com", "music.youtube.com", true},
+		{"example.com:8080", "example.com", true},
+		{"example.com", "example.com.", true},
+		// globs
+		{"*.cdn.*", "img.cdn.net:8080", true},
+		{"*.cdn.*", "cdn.net", false},
+		// registrable domains according to the public suffix list
+		{"domain:www.example.co.uk", "example.co.uk", true},
+		{"domain:www.example.co.uk", "shop.example.co.uk", true},
+		{"domain:www.example.co.uk", "other.co.uk", false},
+		{"domain:example.github.io", "other.github.io", false},
+		// public suffixes and IP addresses have no registrable domain, so only match themselves
+		{"domain:co.uk", "co.uk", true},
+		{"domain:co.uk", "example.co.uk", false},
+		{"domain:192.168.1.1", "192.168.1.1:8080", true},
+		{"domain:192.168.1.1", "10.192.168.1.1", false},
+		{"exact:192.168.1.1", "192.168.1.1", true},
+		{"[::1]:8080", "[::1]:80", true},
+		{"::1", "[::1]", true},
+	}
+	for _, tt := range tests {
+		r, err := hostrule.Parse(tt.rule)
+		if err != nil {
+			t.Errorf("Expected %q to be valid, got %v", tt.rule, err)
+			continue
+		}
+		if got := r.Matches(tt.host); got != tt.exp {
+			t.Errorf("Expected %q to match %q: %v, got %v", tt.rule, tt.host, tt.exp, got)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	for _, s := range []string{"", "domain:", "exact:", "*.", "a.com/path", "a b.com", "[a-*.com", "*.cdn.*:8080", "exact:*.com"} {
+		if _, err := hostrule.Parse(s); err == nil {
+			t.Errorf("Expected %q to be invalid", s)
+		}
+	}
+
+	rules, err := hostrule.ParseRules(" example.com, .example.com,, exact:T.co,domain:example.co.uk,*.CDN.* ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []string{"example.com", "example.com", "exact:t.co", "domain:example.co.uk", "*.cdn.*"}
+	if got := rules.Strings(); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %v, got %v", exp, got)
+	}
+	if got, err := hostrule.ParseRules(strings.Join(exp, ",")); err != nil || !reflect.DeepEqual(got, rules) {
+		t.Errorf("Expected the rules to round-trip, got %v, %v", got, err)
+	}
+}
+
+func TestRulesMatch(t *testing.T) {
+	rules, err := hostrule.ParseRules("*.google.*,domain:google.com,google.com,exact:scholar.google.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		host string
+		exp  string
+	}{
+		{"scholar.google.com", "exact:scholar.google.com"},
+		{"mail.google.com", "google.com"},
+		{"www.google.co.uk", "*.google.*"},
+		{"example.com", ""},
+	}
+	for _, tt := range tests {
+		r, ok := rules.Match(tt.host)
+		if got := r.String(); !ok && tt.exp != "" || ok && got != tt.exp {
+			t.Errorf("Expected %s to match %q, got %q (%v)", tt.host, tt.exp, got, ok)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package gocrawler
+
+// Reports whether links to the host can be crawled. If there is an allowlist, the host must
+// match it, and hosts that match the blacklist are only crawled if they match a more specific
+// rule of the allowlist (e.g. allowing scholar.google.com while blacklisting
+// domain:google.com).
+func (c *Client) isHostAllowed(host string) bool {
+	allow, allowed := c.HostAllowlist.Match(host)
+	if len(c.HostAllowlist) > 0 && !allowed {
+		return false
+	}
+	block, blocked := c.HostBlacklist.Match(host)
+	if !blocked {
+		return true
+	}
+	return allowed && allow.Specificity() > block.Specificity()
+}
@@ -0,0 +1,48 @@
+package gocrawler
+
+import (
+	"testing"
+
+	"github.com/yusufaine/gocrawler/hostrule"
+)
+
+func mustParseHostRules(t *testing.T, s string) hostrule.Rules {
+	t.Helper()
+	rules, err := hostrule.ParseRules(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rules
+}
+
+func TestIsHostAllowed(t *testing.T) {
+	tests := []struct {
+		blacklist string
+		allow     string
+		host      string
+		exp       bool
+	}{
+		{"", "", "example.com", true},
+		{"youtube.com", "", "www.youtube.com", false},
+		{"t.co", "", "microsoft.com", true},
+		// a more specific allow rule overrides a less specific blacklist rule
+		{"domain:google.com", "scholar.google.com", "scholar.google.com", true},
+		{"domain:google.com", "scholar.google.com", "mail.google.com", false},
+		{"google.com", "exact:scholar.google.com", "scholar.google.com", true},
+		{"exact:scholar.google.com", "google.com", "scholar.google.com", false},
+		{"*.cdn.*", "img.cdn.net", "img.cdn.net", true},
+		{"img.cdn.net", "*.cdn.*", "img.cdn.net", false},
+		// with an allowlist, only the allowed hosts are crawled
+		{"", "example.com", "other.com", false},
+		{"", "example.com", "blog.example.com", true},
+	}
+	for _, tt := range tests {
+		c := &Client{
+			HostBlacklist: mustParseHostRules(t, tt.blacklist),
+			HostAllowlist: mustParseHostRules(t, tt.allow),
+		}
+		if got := c.isHostAllowed(tt.host); got != tt.exp {
+			t.Errorf("Expected %s to be allowed with --bl %q --allow %q: %v, got %v", tt.host, tt.blacklist, tt.allow, tt.exp, got)
+		}
+	}
+}
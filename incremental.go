@@ -0,0 +1,341 @@
+package gocrawler
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// State of an incremental recrawl, which revisits the pages of a previous crawl. It is only
+// written to before the crawl starts, see Client.LoadSitemap.
+type recrawlState struct {
+	mu             sync.RWMutex
+	previous       map[string]PageInfo
+	lastMod        map[string]time.Time
+	skipUnchanged  bool
+	sitemapsLoaded map[string]struct{}
+}
+
+func newRecrawlState(previous map[string]PageInfo, skipUnchanged bool) *recrawlState {
+	return &recrawlState{
+		previous:       previous,
+		lastMod:        make(map[string]time.Time),
+		skipUnchanged:  skipUnchanged,
+		sitemapsLoaded: make(map[string]struct{}),
+	}
+}
+
+// LoadPreviousPages reads the "page_info" of a report exported by the examples (e.g.
+// sitemapper), to be used as Config.Previous.
+func LoadPreviousPages(path string) (map[string]PageInfo, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var report struct {
+		Pages map[string]PageInfo `json:"page_info"`
+	}
+	if err := json.Unmarshal(b, &report); err != nil {
+		return nil, err
+	}
+	if report.Pages == nil {
+		return nil, errors.New("report does not contain any page_info")
+	}
+	return report.Pages, nil
+}
+
+// PreviousSeeds returns the pages at depth 0 of a previous crawl, sorted, which are the pages
+// that the seeds of the crawl resolved to.
+func PreviousSeeds(pages map[string]PageInfo) []string {
+	var seeds []string
+	for link, pi := range pages {
+		if pi.Depth == 0 {
+			seeds = append(seeds, link)
+		}
+	}
+	slices.Sort(seeds)
+	return seeds
+}
+
+// Returns the page of the previous crawl, if this is an incremental recrawl.
+func (c *Client) previousPage(link string) (PageInfo, bool) {
+	if c.recrawl.previous == nil {
+		return PageInfo{}, false
+	}
+	pi, ok := c.recrawl.previous[link]
+	return pi, ok
+}
+
+// Reports whether the sitemap has a <lastmod> for the link that is after the page was last
+// checked, and whether the sitemap has a <lastmod> for the link at all.
+func (c *Client) modifiedSince(link string, checkedAt time.Time) (modified, known bool) {
+	c.recrawl.mu.RLock()
+	defer c.recrawl.mu.RUnlock()
+	lastMod, ok := c.recrawl.lastMod[link]
+	if !ok {
+		return false, false
+	}
+	return lastMod.After(checkedAt), true
+}
+
+// Adds the validators of the previous crawl to the request, so that the server can respond
+// with 304 Not Modified instead of the body if the page has not changed.
+func (c *Client) addConditionalHeaders(req *http.Request) {
+	prev, ok := c.previousPage(req.URL.String())
+	if !ok {
+		return
+	}
+	if prev.ETag != "" {
+		req.Header.Set("If-None-Match", prev.ETag)
+	}
+	if prev.LastModified != "" {
+		req.Header.Set("If-Modified-Since", prev.LastModified)
+	}
+}
+
+// Carries the page of the previous crawl forward without requesting it, if the sitemap says
+// that it has not been modified since it was last checked, or if the page that linked to it
+// did not change and unchanged subtrees are skipped. Returns the links of the page, and false
+// if the page has to be requested.
+func (c *Client) carryForward(link, parent string, depth int) ([]string, bool) {
+	prev, ok := c.previousPage(link)
+	if !ok {
+		return nil, false
+	}
+
+	modified, known := c.modifiedSince(link, prev.CheckedAt)
+	if modified {
+		return nil, false
+	}
+	if !known {
+		if !c.recrawl.skipUnchanged {
+			return nil, false
+		}
+		c.PageMutex.RLock()
+		parentPage, ok := c.VisitedPageInfo[parent]
+		c.PageMutex.RUnlock()
+		if !ok || !parentPage.NotModified {
+			return nil, false
+		}
+	}
+
+	log.Debug("carrying forward unchanged page", "link", link)
+	c.RecordAttempt(link, AttemptInfo{
+		Depth:   depth,
+		Parent:  parent,
+		Outcome: OutcomeUnchanged,
+	})
+	return c.storeNotModified(link, parent, depth, prev, nil, prev.CheckedAt), true
+}
+
+// Stores the page of the previous crawl as the page of this crawl, with the depth and parent
+// that it was reached by in this crawl. checkedAt is when the server confirmed that the page
+// was not modified, or when the previous crawl checked it if it was not requested. The body is
+// not received again, so the ContentHash of the page refers to the content store of the
+// previous crawl and the page is left out of the corpus. Its links are not followed if it is a
+// duplicate and Config.SkipNearDups is set, as with pages that are fetched.
+func (c *Client) storeNotModified(link, parent string, depth int, prev PageInfo, hops []RedirectHop, checkedAt time.Time) []string {
+	links := c.filterLinks(slices.Clone(prev.Links), link, depth+1)
+	dupOf := c.findDuplicate(link, prev.ContentHash, prev.SimHash)
+
+	c.PageMutex.Lock()
+	if pi, ok := c.VisitedPageInfo[link]; ok {
+		pi.Depth = min(pi.Depth, depth)
+		c.VisitedPageInfo[link] = pi
+		c.PageMutex.Unlock()
+		return links
+	}
+
+	pi := prev
+	pi.Depth = depth
+	pi.Parent = parent
+	pi.Links = links
+	pi.Redirects = hops
+	pi.DuplicateOf = dupOf
+	pi.NotModified = true
+	if !checkedAt.Equal(prev.CheckedAt) {
+		pi.Checks++
+	}
+	pi.CheckedAt = checkedAt
+	c.VisitedPageInfo[link] = pi
+	c.PageMutex.Unlock()
+
+	c.emit(Event{Type: EventPage, URL: link, Page: &pi})
+	if pi.DuplicateOf != "" && c.skipNearDups {
+		log.Info("not following links of near-duplicate", "link", link, "duplicate of", pi.DuplicateOf)
+		return nil
+	}
+	if c.pageMeta && pi.PageMeta.NoFollow() {
+		c.recordNoFollow(links, link, depth+1)
+		return nil
+	}
+	return links
+}
+
+// Fills in the validators and change history of a page that was fetched, comparing it with
+// the previous crawl, if any.
+func (c *Client) trackChanges(pi *PageInfo, link string, header http.Header, fetchedAt time.Time) {
+	pi.ETag = header.Get("ETag")
+	pi.LastModified = header.Get("Last-Modified")
+	pi.CheckedAt = fetchedAt
+	pi.ChangedAt = fetchedAt
+	pi.Checks = 1
+
+	prev, ok := c.previousPage(link)
+	if !ok {
+		return
+	}
+	pi.Checks = prev.Checks + 1
+	pi.Changes = prev.Changes
+	if pi.ContentHash == prev.ContentHash {
+		pi.ChangedAt = prev.ChangedAt
+	} else {
+		pi.Changes++
+	}
+}
+
+// Sorts the links so that the links most likely to have changed are crawled first, as far
+// as the rate limit allows. Links with a sitemap <lastmod> after they were last checked come
+// first, followed by new links, then known links by how often they changed in the past.
+func (c *Client) prioritise(links []string) []string {
+	if c.recrawl.previous == nil {
+		return links
+	}
+
+	priority := func(link string) float64 {
+		prev, ok := c.previousPage(link)
+		if !ok {
+			return 1
+		}
+		if modified, _ := c.modifiedSince(link, prev.CheckedAt); modified {
+			return 2
+		}
+		return prev.ChangeRate()
+	}
+	priorities := make(map[string]float64, len(links))
+	for _, link := range links {
+		priorities[link] = priority(link)
+	}
+
+	links = slices.Clone(links)
+	slices.SortStableFunc(links, func(a, b string) int {
+		switch pa, pb := priorities[a], priorities[b]; {
+		case pa > pb:
+			return -1
+		case pa < pb:
+			return 1
+		}
+		return 0
+	})
+	return links
+}
+
+// ChangeRate is the estimated probability that the page changed since it was last checked,
+// from the number of times it changed over the crawls that checked it.
+func (pi PageInfo) ChangeRate() float64 {
+	return float64(pi.Changes+1) / float64(pi.Checks+1)
+}
+
+type sitemapXML struct {
+	XMLName xml.Name
+	URLs    []struct {
+		Loc     string `xml:"loc"`
+		LastMod string `xml:"lastmod"`
+	} `xml:"url"`
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+// LoadSitemap reads the <lastmod> of the URLs in the sitemap, following sitemap indexes, so
+// that pages of the previous crawl that have not been modified since are carried forward
+// without requesting them. Returns the number of URLs with a <lastmod>. This must be called
+// before the crawl starts.
+func (c *Client) LoadSitemap(sitemapURL string) (int, error) {
+	c.recrawl.mu.Lock()
+	if _, ok := c.recrawl.sitemapsLoaded[sitemapURL]; ok {
+		c.recrawl.mu.Unlock()
+		return 0, nil
+	}
+	c.recrawl.sitemapsLoaded[sitemapURL] = struct{}{}
+	c.recrawl.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(c.ctx, "GET", sitemapURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	var sm sitemapXML
+	if err := xml.NewDecoder(io.LimitReader(resp.Body, 50<<20)).Decode(&sm); err != nil {
+		return 0, err
+	}
+
+	var count int
+	c.recrawl.mu.Lock()
+	for _, u := range sm.URLs {
+		lastMod, ok := parseLastMod(u.LastMod)
+		if !ok {
+			continue
+		}
+		c.recrawl.lastMod[strings.TrimSpace(u.Loc)] = lastMod
+		count++
+	}
+	c.recrawl.mu.Unlock()
+
+	for _, s := range sm.Sitemaps {
+		n, err := c.LoadSitemap(strings.TrimSpace(s.Loc))
+		if err != nil {
+			log.Warn("unable to load sitemap", "sitemap", s.Loc, "error", err)
+		}
+		count += n
+	}
+	return count, nil
+}
+
+// LoadSitemaps loads the /sitemap.xml of the host of each seed with LoadSitemap, logging the
+// sitemaps that could not be loaded. This must be called before the crawl starts.
+func (c *Client) LoadSitemaps(seeds []string) {
+	for _, seed := range seeds {
+		seedURL, err := url.Parse(seed)
+		if err != nil {
+			continue
+		}
+		sitemapURL := seedURL.Scheme + "://" + seedURL.Host + "/sitemap.xml"
+		if n, err := c.LoadSitemap(sitemapURL); err != nil {
+			log.Warn("unable to load sitemap", "sitemap", sitemapURL, "error", err)
+		} else if n > 0 {
+			log.Info("loaded sitemap", "sitemap", sitemapURL, "urls", n)
+		}
+	}
+}
+
+// Parses the W3C datetime formats allowed in a sitemap <lastmod>.
+func parseLastMod(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04Z07:00", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
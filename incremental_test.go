@@ -0,0 +1,194 @@
+package gocrawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newRecrawlClient(previous map[string]PageInfo, skipUnchanged bool) *Client {
+	return New(context.Background(), &Config{
+		MaxRetries:    1,
+		Timeout:       5 * time.Second,
+		ProxyURL:      &url.URL{},
+		Previous:      previous,
+		SkipUnchanged: skipUnchanged,
+	}, nil, nil)
+}
+
+func TestParseLastMod(t *testing.T) {
+	tests := []struct {
+		s   string
+		exp time.Time
+		ok  bool
+	}{
+		{"2023-10-24", time.Date(2023, 10, 24, 0, 0, 0, 0, time.UTC), true},
+		{" 2023-10-24T15:00Z ", time.Date(2023, 10, 24, 15, 0, 0, 0, time.UTC), true},
+		{"2023-10-24T15:00+08:00", time.Date(2023, 10, 24, 7, 0, 0, 0, time.UTC), true},
+		{"2023-10-24T15:00:30Z", time.Date(2023, 10, 24, 15, 0, 30, 0, time.UTC), true},
+		{"2023-10-24T15:00:30.5-01:00", time.Date(2023, 10, 24, 16, 0, 30, 5e8, time.UTC), true},
+		{"24/10/2023", time.Time{}, false},
+		{"", time.Time{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseLastMod(tt.s)
+		if ok != tt.ok || !got.Equal(tt.exp) {
+			t.Errorf("Expected %q to parse as %v, %v, got %v, %v", tt.s, tt.exp, tt.ok, got, ok)
+		}
+	}
+}
+
+func TestCarryForward(t *testing.T) {
+	checkedAt := time.Date(2023, 10, 24, 0, 0, 0, 0, time.UTC)
+	previous := map[string]PageInfo{
+		"https://a.com/":      {Depth: 0, CheckedAt: checkedAt, Links: []string{"https://a.com/old", "https://a.com/new"}},
+		"https://a.com/old":   {Depth: 1, CheckedAt: checkedAt},
+		"https://a.com/new":   {Depth: 1, CheckedAt: checkedAt},
+		"https://a.com/other": {Depth: 1, CheckedAt: checkedAt},
+	}
+	tests := []struct {
+		name          string
+		skipUnchanged bool
+		link, parent  string
+		exp           bool
+	}{
+		{"unmodified in sitemap", false, "https://a.com/old", "", true},
+		{"modified in sitemap", true, "https://a.com/new", "https://a.com/", false},
+		{"not in sitemap", false, "https://a.com/other", "https://a.com/", false},
+		{"not in sitemap, parent unchanged", true, "https://a.com/other", "https://a.com/", true},
+		{"not in sitemap, parent not visited", true, "https://a.com/other", "https://a.com/x", false},
+		{"not in previous crawl", true, "https://a.com/unknown", "https://a.com/", false},
+	}
+	for _, tt := range tests {
+		c := newRecrawlClient(previous, tt.skipUnchanged)
+		c.recrawl.lastMod["https://a.com/old"] = checkedAt.Add(-time.Hour)
+		c.recrawl.lastMod["https://a.com/new"] = checkedAt.Add(time.Hour)
+		c.VisitedPageInfo["https://a.com/"] = PageInfo{NotModified: true}
+
+		_, ok := c.carryForward(tt.link, tt.parent, 1)
+		if ok != tt.exp {
+			t.Errorf("%s: expected carried forward %v, got %v", tt.name, tt.exp, ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		pi := c.VisitedPageInfo[tt.link]
+		if !pi.NotModified || pi.Parent != tt.parent || pi.Depth != 1 {
+			t.Errorf("%s: expected the page to be carried forward with its new parent, got %+v", tt.name, pi)
+		}
+		if ai := c.Attempts[tt.link]; ai.Outcome != OutcomeUnchanged {
+			t.Errorf("%s: expected the outcome %s, got %s", tt.name, OutcomeUnchanged, ai.Outcome)
+		}
+	}
+}
+
+func TestCarryForwardNearDuplicate(t *testing.T) {
+	// pages carried forward apply the near-duplicate rule like the pages that are fetched
+	checkedAt := time.Date(2023, 10, 24, 0, 0, 0, 0, time.UTC)
+	previous := map[string]PageInfo{
+		"https://a.com/":      {Depth: 0, CheckedAt: checkedAt, ContentHash: "1", Links: []string{"https://a.com/x"}},
+		"https://a.com/?s=1":  {Depth: 0, CheckedAt: checkedAt, ContentHash: "1", Links: []string{"https://a.com/x"}},
+		"https://a.com/other": {Depth: 0, CheckedAt: checkedAt, ContentHash: "2", Links: []string{"https://a.com/x"}},
+	}
+	c := New(context.Background(), &Config{
+		MaxRetries:   1,
+		Timeout:      5 * time.Second,
+		ProxyURL:     &url.URL{},
+		Previous:     previous,
+		SkipNearDups: true,
+	}, nil, nil)
+
+	tests := []struct {
+		link  string
+		dupOf string
+		exp   []string
+	}{
+		{"https://a.com/", "", []string{"https://a.com/x"}},
+		{"https://a.com/?s=1", "https://a.com/", nil},
+		{"https://a.com/other", "", []string{"https://a.com/x"}},
+	}
+	for _, tt := range tests {
+		links := c.storeNotModified(tt.link, "", 0, previous[tt.link], nil, checkedAt)
+		if !reflect.DeepEqual(links, tt.exp) {
+			t.Errorf("%s: expected the links %v to be followed, got %v", tt.link, tt.exp, links)
+		}
+		if got := c.VisitedPageInfo[tt.link].DuplicateOf; got != tt.dupOf {
+			t.Errorf("%s: expected a duplicate of %q, got %q", tt.link, tt.dupOf, got)
+		}
+	}
+}
+
+func TestPrioritise(t *testing.T) {
+	checkedAt := time.Date(2023, 10, 24, 0, 0, 0, 0, time.UTC)
+	c := newRecrawlClient(map[string]PageInfo{
+		"https://a.com/stable":   {CheckedAt: checkedAt, Checks: 9, Changes: 0},
+		"https://a.com/volatile": {CheckedAt: checkedAt, Checks: 9, Changes: 8},
+		"https://a.com/modified": {CheckedAt: checkedAt, Checks: 9, Changes: 0},
+	}, false)
+	c.recrawl.lastMod["https://a.com/modified"] = checkedAt.Add(time.Hour)
+
+	links := []string{"https://a.com/stable", "https://a.com/new", "https://a.com/volatile", "https://a.com/modified", "https://a.com/new2"}
+	exp := []string{"https://a.com/modified", "https://a.com/new", "https://a.com/new2", "https://a.com/volatile", "https://a.com/stable"}
+	if got := c.prioritise(links); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %v, got %v", exp, got)
+	}
+
+	// links are left as they are if this is not a recrawl
+	if got := newRecrawlClient(nil, false).prioritise(links); !reflect.DeepEqual(got, links) {
+		t.Errorf("Expected %v, got %v", links, got)
+	}
+}
+
+func TestLoadSitemaps(t *testing.T) {
+	var srv *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sitemap.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+			<sitemap><loc>%[1]s/pages.xml</loc></sitemap>
+			<sitemap><loc>%[1]s/nested.xml</loc></sitemap>
+			<sitemap><loc>%[1]s/missing.xml</loc></sitemap>
+		</sitemapindex>`, srv.URL)
+	})
+	mux.HandleFunc("/pages.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+			<url><loc>%[1]s/a</loc><lastmod>2023-10-24</lastmod></url>
+			<url><loc>%[1]s/b</loc></url>
+		</urlset>`, srv.URL)
+	})
+	mux.HandleFunc("/nested.xml", func(w http.ResponseWriter, r *http.Request) {
+		// indexes that refer back to a loaded sitemap are not loaded again
+		fmt.Fprintf(w, `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+			<sitemap><loc>%[1]s/sitemap.xml</loc></sitemap>
+			<sitemap><loc>%[1]s/more.xml</loc></sitemap>
+		</sitemapindex>`, srv.URL)
+	})
+	mux.HandleFunc("/more.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+			<url><loc> %[1]s/c </loc><lastmod>2023-10-25T10:00:00+08:00</lastmod></url>
+		</urlset>`, srv.URL)
+	})
+	srv = httptest.NewServer(mux)
+	defer srv.Close()
+
+	c := newRecrawlClient(map[string]PageInfo{}, false)
+	c.LoadSitemaps([]string{srv.URL + "/blog/", srv.URL + "/"})
+
+	exp := map[string]time.Time{
+		srv.URL + "/a": time.Date(2023, 10, 24, 0, 0, 0, 0, time.UTC),
+		srv.URL + "/c": time.Date(2023, 10, 25, 2, 0, 0, 0, time.UTC),
+	}
+	if len(c.recrawl.lastMod) != len(exp) {
+		t.Errorf("Expected %d lastmods, got %v", len(exp), c.recrawl.lastMod)
+	}
+	for link, lastMod := range exp {
+		if got := c.recrawl.lastMod[link]; !got.Equal(lastMod) {
+			t.Errorf("Expected the lastmod of %s to be %v, got %v", link, lastMod, got)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package rhttp
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Sent with every request that does not set its own Accept-Encoding. Setting it disables the
+// transparent gzip decompression of the transport, responses are decoded by decodeBody instead.
+const acceptEncoding = "gzip, deflate, br, zstd"
+
+var supportedEncodings = map[string]struct{}{
+	"gzip":    {},
+	"x-gzip":  {},
+	"deflate": {},
+	"br":      {},
+	"zstd":    {},
+}
+
+// DecodedBody is the body of a response decoded according to its Content-Encoding, which counts
+// the bytes read from the connection and the bytes that they decoded to.
+type DecodedBody struct {
+	wire     *countingReader
+	rc       io.ReadCloser
+	encoding []string
+	decoder  io.Reader
+	closers  []func()
+	decoded  int64
+	err      error
+}
+
+// Encoding returns the Content-Encoding of the response, e.g. "br", empty if it was not encoded.
+func (b *DecodedBody) Encoding() string {
+	return strings.Join(b.encoding, ", ")
+}
+
+// WireBytes returns the number of bytes of the body read from the connection so far.
+func (b *DecodedBody) WireBytes() int64 {
+	return b.wire.n
+}
+
+// DecodedBytes returns the number of bytes of the body after decoding read so far.
+func (b *DecodedBody) DecodedBytes() int64 {
+	return b.decoded
+}
+
+func (b *DecodedBody) Read(p []byte) (int, error) {
+	// decoders are created on the first read as some (e.g. gzip) read the header immediately,
+	// which fails for empty bodies
+	if b.decoder == nil && b.err == nil {
+		b.decoder, b.err = b.newDecoder()
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+	n, err := b.decoder.Read(p)
+	b.decoded += int64(n)
+	return n, err
+}
+
+func (b *DecodedBody) Close() error {
+	for _, closeFn := range b.closers {
+		closeFn()
+	}
+	return b.rc.Close()
+}
+
+// Decodes the encodings in the reverse order that they were applied.
+func (b *DecodedBody) newDecoder() (io.Reader, error) {
+	var r io.Reader = b.wire
+	for i := len(b.encoding) - 1; i >= 0; i-- {
+		switch b.encoding[i] {
+		case "gzip", "x-gzip":
+			zr, err := gzip.NewReader(r)
+			if err != nil {
+				return nil, err
+			}
+			b.closers = append(b.closers, func() { zr.Close() })
+			r = zr
+		case "deflate":
+			// deflate is meant to be zlib-wrapped, but some servers send raw deflate
+			br := bufio.NewReader(r)
+			if header, err := br.Peek(2); err == nil && isZlibHeader(header) {
+				zr, err := zlib.NewReader(br)
+				if err != nil {
+					return nil, err
+				}
+				b.closers = append(b.closers, func() { zr.Close() })
+				r = zr
+			} else {
+				fr := flate.NewReader(br)
+				b.closers = append(b.closers, func() { fr.Close() })
+				r = fr
+			}
+		case "br":
+			r = brotli.NewReader(r)
+		case "zstd":
+			zr, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+			if err != nil {
+				return nil, err
+			}
+			b.closers = append(b.closers, zr.Close)
+			r = zr
+		default:
+			return nil, fmt.Errorf("unsupported content encoding %q", b.encoding[i])
+		}
+	}
+	return r, nil
+}
+
+func isZlibHeader(h []byte) bool {
+	return h[0]&0x0f == 8 && (uint16(h[0])<<8|uint16(h[1]))%31 == 0
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// Replaces the body of the response with a DecodedBody. If the body was encoded with supported
+// encodings, the Content-Encoding and Content-Length headers are removed as the transport does
+// when it decompresses a body, so that the headers describe the body that is read.
+func decodeBody(resp *http.Response) {
+	var encoding []string
+	for _, v := range resp.Header.Values("Content-Encoding") {
+		for _, e := range strings.Split(v, ",") {
+			if e = strings.ToLower(strings.TrimSpace(e)); e != "" && e != "identity" {
+				encoding = append(encoding, e)
+			}
+		}
+	}
+	// bodies with an encoding that cannot be decoded are read as they are
+	for _, e := range encoding {
+		if _, ok := supportedEncodings[e]; !ok {
+			encoding = nil
+			break
+		}
+	}
+
+	body := &DecodedBody{
+		wire:     &countingReader{r: resp.Body},
+		rc:       resp.Body,
+		encoding: encoding,
+	}
+	if len(encoding) == 0 {
+		body.decoder = body.wire
+	} else {
+		resp.Header.Del("Content-Encoding")
+		resp.Header.Del("Content-Length")
+		resp.ContentLength = -1
+		resp.Uncompressed = true
+	}
+	resp.Body = body
+}
+
+// Decoded returns the DecodedBody of a response of the client, if any, which may be wrapped to
+// record it in the HAR log.
+func Decoded(body io.ReadCloser) (*DecodedBody, bool) {
+	if hb, ok := body.(*harBody); ok {
+		body = hb.ReadCloser
+	}
+	db, ok := body.(*DecodedBody)
+	return db, ok
+}
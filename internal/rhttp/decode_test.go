@@ -0,0 +1,92 @@
+package rhttp_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"github.com/yusufaine/gocrawler/internal/rhttp"
+)
+
+func TestDecodedBody(t *testing.T) {
+	plain := strings.Repeat("hello world ", 100)
+	encoders := map[string]func(w io.Writer) io.WriteCloser{
+		"gzip": func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+		"deflate": func(w io.Writer) io.WriteCloser {
+			fw, _ := flate.NewWriter(w, flate.DefaultCompression)
+			return fw
+		},
+		"br": func(w io.Writer) io.WriteCloser { return brotli.NewWriter(w) },
+		"zstd": func(w io.Writer) io.WriteCloser {
+			zw, _ := zstd.NewWriter(w)
+			return zw
+		},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "zstd") {
+			t.Errorf("Expected zstd to be accepted, got %q", r.Header.Get("Accept-Encoding"))
+		}
+		encoding := strings.TrimPrefix(r.URL.Path, "/")
+		newEncoder, ok := encoders[encoding]
+		if !ok {
+			_, _ = io.WriteString(w, plain)
+			return
+		}
+		w.Header().Set("Content-Encoding", encoding)
+		ew := newEncoder(w)
+		_, _ = io.WriteString(ew, plain)
+		ew.Close()
+	}))
+	defer srv.Close()
+
+	hc := rhttp.New()
+	for _, encoding := range []string{"gzip", "deflate", "br", "zstd", "identity"} {
+		t.Run(encoding, func(t *testing.T) {
+			req, err := http.NewRequest("GET", srv.URL+"/"+encoding, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := hc.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(body, []byte(plain)) {
+				t.Fatalf("Expected the decoded body, got %q", body)
+			}
+			if resp.Header.Get("Content-Encoding") != "" {
+				t.Errorf("Expected Content-Encoding to be removed, got %q", resp.Header.Get("Content-Encoding"))
+			}
+
+			db, ok := rhttp.Decoded(resp.Body)
+			if !ok {
+				t.Fatal("Expected the body to be a DecodedBody")
+			}
+			if db.DecodedBytes() != int64(len(plain)) {
+				t.Errorf("Expected %d decoded bytes, got %d", len(plain), db.DecodedBytes())
+			}
+			if encoding == "identity" {
+				if db.Encoding() != "" || db.WireBytes() != db.DecodedBytes() {
+					t.Errorf("Expected the body to be read as is, got %q with %d bytes", db.Encoding(), db.WireBytes())
+				}
+				return
+			}
+			if db.Encoding() != encoding || db.WireBytes() == 0 || db.WireBytes() >= db.DecodedBytes() {
+				t.Errorf("Expected %s to be compressed, got %q with %d of %d bytes", encoding, db.Encoding(), db.WireBytes(), db.DecodedBytes())
+			}
+		})
+	}
+}
@@ -0,0 +1,336 @@
+package rhttp
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// HAR is an HTTP Archive (HAR) 1.2 document, see http://www.softwareishard.com/blog/har-12-spec/.
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+type HARLog struct {
+	Version string     `json:"version"`
+	Creator HARCreator `json:"creator"`
+	Entries []HAREntry `json:"entries"`
+}
+
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// HAREntry is a single request and its response. Requests that failed without a response have
+// a response with status 0 and the error in the "_error" field.
+type HAREntry struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HARTimings  `json:"timings"`
+	ServerIPAddress string      `json:"serverIPAddress,omitempty"`
+	Connection      string      `json:"connection,omitempty"`
+	Error           string      `json:"_error,omitempty"`
+}
+
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type HARResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	Content     HARContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+// HARContent is the body of the response as it was read by the caller, Size is the number of
+// bytes read after decompression.
+type HARContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HARTimings are in milliseconds, -1 if the phase did not happen (e.g. dns and connect when a
+// connection is reused). connect includes ssl, as required by the spec.
+type HARTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	SSL     float64 `json:"ssl"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// HARRecorder records every attempt made by the client, including retries, as HAR entries. An
+// entry is only complete once the response body has been read or closed.
+type HARRecorder struct {
+	mu         sync.Mutex
+	withBodies bool
+	entries    []HAREntry
+}
+
+// NewHARRecorder creates a recorder, response bodies are only included if withBodies is set.
+func NewHARRecorder(withBodies bool) *HARRecorder {
+	return &HARRecorder{withBodies: withBodies}
+}
+
+// HAR returns the entries recorded so far sorted by the time the request was started.
+func (h *HARRecorder) HAR() HAR {
+	h.mu.Lock()
+	entries := slices.Clone(h.entries)
+	h.mu.Unlock()
+
+	slices.SortStableFunc(entries, func(a, b HAREntry) int {
+		return a.StartedDateTime.Compare(b.StartedDateTime)
+	})
+	if entries == nil {
+		entries = []HAREntry{}
+	}
+	return HAR{Log: HARLog{
+		Version: "1.2",
+		Creator: HARCreator{Name: "gocrawler", Version: "1.0"},
+		Entries: entries,
+	}}
+}
+
+func (h *HARRecorder) add(e HAREntry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries = append(h.entries, e)
+}
+
+// Times of each phase of a request, collected with httptrace. The mutex is needed as the
+// transport may dial multiple addresses concurrently.
+type harTrace struct {
+	mu sync.Mutex
+
+	start, dnsStart, dnsDone, connStart, connDone   time.Time
+	tlsStart, tlsDone, gotConn, wroteReq, firstByte time.Time
+
+	serverIP string
+	connID   string
+}
+
+// Adds a trace to the request's context to time each phase of the request.
+func (t *harTrace) withTrace(req *http.Request) *http.Request {
+	t.start = time.Now()
+	now := func(field *time.Time) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		*field = time.Now()
+	}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { now(&t.dnsStart) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { now(&t.dnsDone) },
+		ConnectStart:         func(_, _ string) { now(&t.connStart) },
+		ConnectDone:          func(_, _ string, _ error) { now(&t.connDone) },
+		TLSHandshakeStart:    func() { now(&t.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { now(&t.tlsDone) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { now(&t.wroteReq) },
+		GotFirstResponseByte: func() { now(&t.firstByte) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			now(&t.gotConn)
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.serverIP, _, _ = net.SplitHostPort(info.Conn.RemoteAddr().String())
+			t.connID = info.Conn.LocalAddr().String()
+		},
+	}))
+}
+
+func (t *harTrace) timings(end time.Time) HARTimings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ms := func(from, to time.Time) float64 {
+		if from.IsZero() || to.IsZero() {
+			return -1
+		}
+		return float64(to.Sub(from).Microseconds()) / 1000
+	}
+
+	connDone := t.connDone
+	if t.tlsDone.After(connDone) {
+		connDone = t.tlsDone
+	}
+	ht := HARTimings{
+		DNS:     ms(t.dnsStart, t.dnsDone),
+		Connect: ms(t.connStart, connDone),
+		SSL:     ms(t.tlsStart, t.tlsDone),
+		Send:    ms(t.gotConn, t.wroteReq),
+		Wait:    ms(t.wroteReq, t.firstByte),
+		Receive: ms(t.firstByte, end),
+	}
+
+	// time spent waiting for a connection, excluding the time taken to create one
+	ht.Blocked = ms(t.start, t.gotConn)
+	for _, d := range []float64{ht.DNS, ht.Connect} {
+		if d > 0 && ht.Blocked >= d {
+			ht.Blocked -= d
+		}
+	}
+
+	// only the optional phases can be -1
+	ht.Send, ht.Wait, ht.Receive = max(ht.Send, 0), max(ht.Wait, 0), max(ht.Receive, 0)
+	ht.Blocked = roundMs(ht.Blocked)
+	return ht
+}
+
+// Records the request and response, or error, once the response body is done with.
+func (h *HARRecorder) record(req *http.Request, t *harTrace, resp *http.Response, err error) *http.Response {
+	e := HAREntry{
+		StartedDateTime: t.start,
+		Request: HARRequest{
+			Method:      req.Method,
+			URL:         req.URL.String(),
+			HTTPVersion: "HTTP/1.1",
+			Cookies:     []HARNameValue{},
+			Headers:     nameValues(req.Header),
+			QueryString: nameValues(http.Header(req.URL.Query())),
+			HeadersSize: -1,
+			BodySize:    0,
+		},
+		Response: HARResponse{
+			Cookies:     []HARNameValue{},
+			Headers:     []HARNameValue{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		ServerIPAddress: t.serverIP,
+		Connection:      t.connID,
+	}
+	if err != nil || resp == nil {
+		if err != nil {
+			e.Error = err.Error()
+		}
+		h.finish(e, t, time.Now())
+		return resp
+	}
+
+	e.Request.HTTPVersion = resp.Proto
+	e.Response.Status = resp.StatusCode
+	e.Response.StatusText = http.StatusText(resp.StatusCode)
+	e.Response.HTTPVersion = resp.Proto
+	e.Response.Headers = nameValues(resp.Header)
+	e.Response.Content.MimeType = resp.Header.Get("Content-Type")
+	if loc, err := resp.Location(); err == nil {
+		e.Response.RedirectURL = loc.String()
+	}
+
+	resp.Body = &harBody{ReadCloser: resp.Body, h: h, entry: e, trace: t, compressed: !resp.Uncompressed}
+	return resp
+}
+
+func (h *HARRecorder) finish(e HAREntry, t *harTrace, end time.Time) {
+	e.Timings = t.timings(end)
+	for _, d := range []float64{e.Timings.Blocked, e.Timings.DNS, e.Timings.Connect, e.Timings.Send, e.Timings.Wait, e.Timings.Receive} {
+		if d > 0 {
+			e.Time += d
+		}
+	}
+	e.Time = roundMs(e.Time)
+	h.add(e)
+}
+
+// Counts (and keeps, if bodies are recorded) the bytes of the body as they are read, the entry is
+// recorded when the body has been fully read or closed.
+type harBody struct {
+	io.ReadCloser
+	h          *HARRecorder
+	entry      HAREntry
+	trace      *harTrace
+	compressed bool
+
+	buf  bytes.Buffer
+	n    int
+	once sync.Once
+}
+
+func (b *harBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += n
+	if b.h.withBodies {
+		b.buf.Write(p[:n])
+	}
+	if err == io.EOF {
+		b.done()
+	}
+	return n, err
+}
+
+func (b *harBody) Close() error {
+	b.done()
+	return b.ReadCloser.Close()
+}
+
+func (b *harBody) done() {
+	b.once.Do(func() {
+		b.entry.Response.Content.Size = b.n
+		// the transport removes the Content-Length of bodies that it decompressed, and so does
+		// the client for bodies that it decoded, which know how many bytes were transferred
+		if db, ok := b.ReadCloser.(*DecodedBody); ok {
+			b.entry.Response.BodySize = int(db.WireBytes())
+		} else if b.compressed {
+			b.entry.Response.BodySize = b.n
+		}
+		if b.h.withBodies && b.buf.Len() > 0 {
+			if utf8.Valid(b.buf.Bytes()) {
+				b.entry.Response.Content.Text = b.buf.String()
+			} else {
+				b.entry.Response.Content.Text = base64.StdEncoding.EncodeToString(b.buf.Bytes())
+				b.entry.Response.Content.Encoding = "base64"
+			}
+		}
+		b.h.finish(b.entry, b.trace, time.Now())
+	})
+}
+
+// Rounds to the nearest microsecond to remove floating point errors from adding timings.
+func roundMs(ms float64) float64 {
+	return math.Round(ms*1000) / 1000
+}
+
+func nameValues(h http.Header) []HARNameValue {
+	nvs := make([]HARNameValue, 0, len(h))
+	for k, vs := range h {
+		for _, v := range vs {
+			nvs = append(nvs, HARNameValue{Name: k, Value: v})
+		}
+	}
+	slices.SortStableFunc(nvs, func(a, b HARNameValue) int { return strings.Compare(a.Name, b.Name) })
+	return nvs
+}
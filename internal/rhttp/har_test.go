@@ -0,0 +1,71 @@
+package rhttp_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/yusufaine/gocrawler/internal/rhttp"
+)
+
+func TestHARRecorder(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = io.WriteString(w, "hello")
+	}))
+	defer srv.Close()
+
+	har := rhttp.NewHARRecorder(true)
+	hc := rhttp.New(
+		rhttp.WithHARRecorder(har),
+		rhttp.WithBackoffPolicy(func(_, _, _ int) time.Duration { return 0 }),
+	)
+	req, err := http.NewRequest("GET", srv.URL+"/a?q=1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the entry is only recorded once the body has been read
+	if _, err := io.ReadAll(resp.Body); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	entries := har.HAR().Log.Entries
+	if len(entries) != 2 {
+		t.Fatalf("Expected the retried and final request to be recorded, got %d entries", len(entries))
+	}
+
+	retried, final := entries[0], entries[1]
+	if retried.Response.Status != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 for the retried request, got %d", retried.Response.Status)
+	}
+	if final.Response.Status != http.StatusOK || final.Response.Content.Text != "hello" || final.Response.Content.Size != 5 {
+		t.Errorf("Expected 200 with body hello, got %d with %q", final.Response.Status, final.Response.Content.Text)
+	}
+	if len(final.Request.QueryString) != 1 || final.Request.QueryString[0] != (rhttp.HARNameValue{Name: "q", Value: "1"}) {
+		t.Errorf("Expected query string q=1, got %+v", final.Request.QueryString)
+	}
+	if final.ServerIPAddress != "127.0.0.1" {
+		t.Errorf("Expected server IP 127.0.0.1, got %s", final.ServerIPAddress)
+	}
+
+	// the second request reuses the connection, so it should not have dns or connect timings
+	if retried.Timings.Connect < 0 || final.Timings.Connect != -1 {
+		t.Errorf("Expected only the first request to connect, got %v and %v", retried.Timings.Connect, final.Timings.Connect)
+	}
+	if final.Timings.Send < 0 || final.Timings.Wait < 0 || final.Timings.Receive < 0 {
+		t.Errorf("Expected send, wait and receive to be >= 0, got %+v", final.Timings)
+	}
+}
@@ -21,13 +21,14 @@ type Client struct {
 	maxWaitMs     int
 	retryPol      RetryPolicy
 	backoffPol    BackoffPolicy
+	har           *HARRecorder
 }
 
 // By default, the client will retry 3 times with a linear backoff between 100ms
 // and 1000ms. Refer to BackoffPolicy and RetryPolicy for more information.
 func New(opts ...RHTTPOption) *Client {
 	c := &Client{
-		cl:            http.DefaultClient,
+		cl:            &http.Client{},
 		maxRetryCount: defaultMaxRetryCount,
 		minWaitMs:     defaultMinWaitMs,
 		maxWaitMs:     defaultMaxWaitMs,
@@ -44,16 +45,23 @@ func New(opts ...RHTTPOption) *Client {
 // TODO: There may exist an issue if the request has a body
 func (c *Client) Do(req *http.Request) (resp *http.Response, err error) {
 	req.Header.Set("User-Agent", userAgent)
+	if req.Header.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
 	for i := 0; i < c.maxRetryCount; i++ {
 		select {
 		case <-req.Context().Done():
 			return resp, req.Context().Err()
 		default:
-			resp, err = c.cl.Do(req)
+			resp, err = c.do(req)
 			retry, err := c.retryPol(resp, err)
 			if !retry {
 				return resp, err
 			}
+			// the response is discarded, close it so that the connection can be reused
+			if resp != nil && i < c.maxRetryCount-1 {
+				resp.Body.Close()
+			}
 			wait := c.backoffPol(c.minWaitMs, c.maxWaitMs, i)
 			log.Warn("retrying request", "attempt", i+1, "wait", wait, "link", req.URL.String())
 			time.Sleep(wait)
@@ -61,3 +69,22 @@ func (c *Client) Do(req *http.Request) (resp *http.Response, err error) {
 	}
 	return resp, err
 }
+
+// Sends the request once, decoding the body of the response and recording it if a HAR recorder
+// is set.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	if c.har == nil {
+		resp, err := c.cl.Do(req)
+		if err == nil {
+			decodeBody(resp)
+		}
+		return resp, err
+	}
+
+	t := &harTrace{}
+	resp, err := c.cl.Do(t.withTrace(req))
+	if err == nil {
+		decodeBody(resp)
+	}
+	return c.har.record(req, t, resp, err), err
+}
@@ -15,6 +15,21 @@ func WithBackoffPolicy(bp BackoffPolicy) RHTTPOption {
 	}
 }
 
+// WithCheckRedirect sets the redirect policy of the underlying http.Client. Returning
+// http.ErrUseLastResponse from the function stops the client from following redirects.
+func WithCheckRedirect(fn func(req *http.Request, via []*http.Request) error) RHTTPOption {
+	return func(c *Client) {
+		c.cl.CheckRedirect = fn
+	}
+}
+
+// WithHARRecorder records every request made by the client, including retries, to the recorder.
+func WithHARRecorder(h *HARRecorder) RHTTPOption {
+	return func(c *Client) {
+		c.har = h
+	}
+}
+
 func WithMaxRetries(maxRetries int) RHTTPOption {
 	return func(c *Client) {
 		c.maxRetryCount = maxRetries
@@ -2,65 +2,138 @@ package gocrawler
 
 import (
 	"bytes"
+	"io"
 	"net/url"
 	"slices"
 	"strings"
 
-	"github.com/PuerkitoBio/goquery"
 	"github.com/charmbracelet/log"
+	"golang.org/x/net/html"
 )
 
-// Takes in a map of blacklisted hosts and the response body and returns a slice of links
+// Takes in the crawler, the current link and the response body, transcoded to UTF-8 if it is
+// text, and returns a slice of links
 type LinkExtractor func(c *Client, currLink string, resp []byte) []string
 
-// DefaultLinkExtractor looks for <a href="..."> tags and extracts the link if the host
-// is not blacklisted. This function assumes that if the href value is a relative path,
-// it is relative to the current URL.
+// DefaultLinkExtractor looks for <a href="..."> tags and extracts the link. Links to
+// blacklisted hosts or out of Config.Scope are removed and recorded by the crawler after
+// extraction. This function assumes that if the href value is a relative path, it is relative
+// to the current URL.
 func DefaultLinkExtractor(c *Client, currLink string, resp []byte) []string {
-	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(resp))
+	currURL, err := url.Parse(currLink)
 	if err != nil {
-		log.Error("unable to parse response body", "error", err)
 		return nil
 	}
 
-	currURL, err := url.Parse(currLink)
-	if err != nil {
+	return ExtractLinks(currURL, bytes.NewReader(resp))
+}
+
+// Anchor is an <a href="..."> link and the text between its tags, with whitespace collapsed.
+type Anchor struct {
+	URL  string
+	Text string
+}
+
+// ExtractLinks returns the sorted, unique links of all <a href="..."> tags, see ExtractAnchors.
+func ExtractLinks(base *url.URL, r io.Reader) []string {
+	linkSet := make(map[string]struct{})
+	for _, a := range ExtractAnchors(base, r) {
+		linkSet[a.URL] = struct{}{}
+	}
+
+	links := make([]string, 0, len(linkSet))
+	for k := range linkSet {
+		links = append(links, k)
+	}
+	slices.Sort(links)
+
+	return links
+}
+
+// Groups the anchors of the HTML by link, only keeping the links that are in the slice.
+func linkAnchors(currLink string, text []byte, links []string) map[string]LinkAnchors {
+	base, err := url.Parse(currLink)
+	if err != nil || len(links) == 0 {
 		return nil
 	}
 
-	linkSet := make(map[string]struct{})
-	doc.Find("a").Each(func(i int, s *goquery.Selection) {
-		outLink, ok := s.Attr("href")
-		if !ok {
-			return
+	keep := make(map[string]struct{}, len(links))
+	for _, link := range links {
+		keep[link] = struct{}{}
+	}
+	anchors := make(map[string]LinkAnchors)
+	for _, a := range ExtractAnchors(base, bytes.NewReader(text)) {
+		if _, ok := keep[a.URL]; !ok {
+			continue
 		}
-
-		// skip if link cannot be parsed
-		outURL, err := url.Parse(strings.TrimSpace(outLink))
-		if err != nil {
-			return
+		la := anchors[a.URL]
+		la.Count++
+		if a.Text != "" && !slices.Contains(la.Texts, a.Text) {
+			la.Texts = append(la.Texts, a.Text)
 		}
+		anchors[a.URL] = la
+	}
+	if len(anchors) == 0 {
+		return nil
+	}
+	return anchors
+}
 
-		// if host is empty, likely to be a relative path
-		if outURL.Host == "" && outURL.Path != "" {
-			tmpPath := outURL.Path
-			outURL = currURL
-			outURL.Path = tmpPath
+// ExtractAnchors tokenises the HTML without building the document tree, and returns all
+// <a href="..."> tags in the order they appear, including duplicates. Relative links
+// are resolved against the base URL and fragments are removed as they refer to the same page.
+// The crawler calls it with the body that it has already read, up to Config.MaxBodyBytes.
+func ExtractAnchors(base *url.URL, r io.Reader) []Anchor {
+	var (
+		anchors []Anchor
+		inA     bool
+	)
+	z := html.NewTokenizer(r)
+	for tt := z.Next(); tt != html.ErrorToken; tt = z.Next() {
+		switch tt {
+		case html.TextToken:
+			if inA {
+				last := &anchors[len(anchors)-1]
+				last.Text = strings.Join(append(strings.Fields(last.Text), strings.Fields(string(z.Text()))...), " ")
+			}
+			continue
+		case html.EndTagToken:
+			if name, _ := z.TagName(); string(name) == "a" {
+				inA = false
+			}
+			continue
+		case html.StartTagToken, html.SelfClosingTagToken:
+		default:
+			continue
 		}
 
-		// skip if host is blacklisted
-		if _, ok := c.HostBlacklist[outURL.Host]; ok {
-			return
+		name, hasAttr := z.TagName()
+		if string(name) != "a" {
+			continue
 		}
+		inA = false
+		for hasAttr {
+			var key, val []byte
+			key, val, hasAttr = z.TagAttr()
+			if string(key) != "href" {
+				continue
+			}
 
-		linkSet[outURL.String()] = struct{}{}
-	})
-
-	links := make([]string, 0, len(linkSet))
-	for k := range linkSet {
-		links = append(links, k)
+			// skip if link cannot be parsed
+			outURL, err := url.Parse(strings.TrimSpace(string(val)))
+			if err != nil {
+				break
+			}
+			outURL = base.ResolveReference(outURL)
+			outURL.Fragment = ""
+			anchors = append(anchors, Anchor{URL: outURL.String()})
+			inA = tt == html.StartTagToken
+			break
+		}
+	}
+	if err := z.Err(); err != io.EOF {
+		log.Error("unable to parse response body", "error", err)
 	}
-	slices.Sort(links)
 
-	return links
+	return anchors
 }
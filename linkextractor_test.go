@@ -0,0 +1,23 @@
+package gocrawler
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLinkAnchors(t *testing.T) {
+	body := []byte(`<a href="/b">B <i>page</i></a> <a href="/b#top">top</a> <a href="/b">B page</a>
+		<a href="https://c.com/">C</a> <a href="/img"><img src="x.png"></a>`)
+	links := []string{"https://a.com/b", "https://a.com/img"}
+
+	exp := map[string]LinkAnchors{
+		"https://a.com/b":   {Count: 3, Texts: []string{"B page", "top"}},
+		"https://a.com/img": {Count: 1},
+	}
+	if got := linkAnchors("https://a.com/", body, links); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %+v, got %+v", exp, got)
+	}
+	if got := linkAnchors("https://a.com/", body, nil); got != nil {
+		t.Errorf("Expected no anchors without links, got %+v", got)
+	}
+}
@@ -0,0 +1,283 @@
+package gocrawler
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// PageMeta is the metadata of an HTML page, extracted during the crawl if
+// Config.ExtractPageMeta is set so that it does not have to be parsed from the stored body.
+// Pages of other media types only have the Robots directives of their X-Robots-Tag header.
+type PageMeta struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Lang        string `json:"lang,omitempty"`
+
+	// Canonical is the absolute URL of <link rel="canonical">, if any. Robots are the lowercase
+	// directives of <meta name="robots"> and the X-Robots-Tag header, e.g. "noindex".
+	Canonical string   `json:"canonical,omitempty"`
+	Robots    []string `json:"robots,omitempty"`
+
+	// Headings are the <h1> to <h6> of the page in document order.
+	Headings []Heading `json:"headings,omitempty"`
+
+	// OpenGraph and Twitter are the first value of each og:* and twitter:* property, keyed by
+	// the full property name, e.g. "og:title". JSONLD are the valid JSON-LD scripts as is.
+	OpenGraph map[string]string `json:"open_graph,omitempty"`
+	Twitter   map[string]string `json:"twitter,omitempty"`
+	JSONLD    []json.RawMessage `json:"json_ld,omitempty"`
+}
+
+// Heading is a heading of a page, with its level (1 for <h1>) and text with whitespace
+// collapsed.
+type Heading struct {
+	Level int    `json:"level"`
+	Text  string `json:"text"`
+}
+
+// NoIndex reports whether the page asked not to be indexed, with "noindex" or "none".
+func (m *PageMeta) NoIndex() bool {
+	return m.hasRobots("noindex")
+}
+
+// NoFollow reports whether the page asked for its links not to be followed, with "nofollow" or
+// "none".
+func (m *PageMeta) NoFollow() bool {
+	return m.hasRobots("nofollow")
+}
+
+func (m *PageMeta) hasRobots(directive string) bool {
+	if m == nil {
+		return false
+	}
+	return slices.Contains(m.Robots, directive) || slices.Contains(m.Robots, "none")
+}
+
+// IsCanonical reports whether the page is the canonical version of itself, which is the case
+// if it does not declare a canonical URL.
+func (m *PageMeta) IsCanonical(link string) bool {
+	return m == nil || m.Canonical == "" || m.Canonical == link
+}
+
+// Indexable reports whether the page can be indexed under the link, which is the case if it is
+// not noindex and it is its own canonical version.
+func (m *PageMeta) Indexable(link string) bool {
+	return !m.NoIndex() && m.IsCanonical(link)
+}
+
+// ExtractPageMeta parses the HTML and returns its metadata. The canonical URL is resolved
+// against the base URL and its fragment is removed, as ExtractAnchors does for links.
+func ExtractPageMeta(base *url.URL, r io.Reader) *PageMeta {
+	doc, err := html.Parse(r)
+	if err != nil {
+		log.Error("unable to parse response body", "error", err)
+		return nil
+	}
+
+	m := &PageMeta{}
+	var walk func(n *html.Node, inSVG bool)
+	walk = func(n *html.Node, inSVG bool) {
+		if n.Type == html.ElementNode {
+			switch n.DataAtom {
+			case atom.Html:
+				m.Lang = strings.TrimSpace(attr(n, "lang"))
+			case atom.Svg:
+				inSVG = true
+			case atom.Title:
+				// <svg> has its own <title> elements
+				if m.Title == "" && !inSVG {
+					m.Title = nodeText(n)
+				}
+			case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+				if text := nodeText(n); text != "" {
+					m.Headings = append(m.Headings, Heading{Level: int(n.Data[1] - '0'), Text: text})
+				}
+			case atom.Meta:
+				m.addMeta(n)
+			case atom.Link:
+				if m.Canonical == "" && hasToken(attr(n, "rel"), "canonical") {
+					m.Canonical = resolveLink(base, attr(n, "href"))
+				}
+			case atom.Script:
+				if strings.EqualFold(strings.TrimSpace(attr(n, "type")), "application/ld+json") {
+					m.addJSONLD(n)
+				}
+				return
+			case atom.Style, atom.Template:
+				return
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child, inSVG)
+		}
+	}
+	walk(doc, false)
+
+	return m
+}
+
+func (m *PageMeta) addMeta(n *html.Node) {
+	content := strings.TrimSpace(attr(n, "content"))
+	name := strings.ToLower(strings.TrimSpace(attr(n, "name")))
+	// Open Graph uses property, but some sites use name for both
+	property := strings.ToLower(strings.TrimSpace(attr(n, "property")))
+	if property == "" {
+		property = name
+	}
+
+	switch {
+	case name == "description" && m.Description == "":
+		m.Description = content
+	case name == "robots":
+		m.addRobots(content)
+	case strings.HasPrefix(property, "og:"):
+		m.OpenGraph = setFirst(m.OpenGraph, property, content)
+	case strings.HasPrefix(property, "twitter:"):
+		m.Twitter = setFirst(m.Twitter, property, content)
+	}
+}
+
+// Adds the comma-separated directives, ignoring duplicates.
+func (m *PageMeta) addRobots(directives string) {
+	for _, d := range strings.Split(directives, ",") {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if d != "" && !slices.Contains(m.Robots, d) {
+			m.Robots = append(m.Robots, d)
+		}
+	}
+}
+
+// Adds the directives of the X-Robots-Tag headers that apply to all crawlers, directives for a
+// specific crawler (e.g. "googlebot: noindex") are ignored.
+func (m *PageMeta) addRobotsHeader(header http.Header) {
+	for _, v := range header.Values("X-Robots-Tag") {
+		// "unavailable_after: <date>" is a directive, not a crawler
+		agent, _, ok := strings.Cut(v, ":")
+		if ok && !strings.Contains(agent, ",") && !strings.EqualFold(strings.TrimSpace(agent), "unavailable_after") {
+			continue
+		}
+		m.addRobots(v)
+	}
+}
+
+func (m *PageMeta) addJSONLD(n *html.Node) {
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.TextNode {
+			b.WriteString(child.Data)
+		}
+	}
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, []byte(strings.TrimSpace(b.String()))); err != nil {
+		log.Debug("skipping invalid json-ld", "error", err)
+		return
+	}
+	m.JSONLD = append(m.JSONLD, json.RawMessage(compact.Bytes()))
+}
+
+func setFirst(m map[string]string, key, value string) map[string]string {
+	if m == nil {
+		m = make(map[string]string)
+	}
+	if _, ok := m[key]; !ok {
+		m[key] = value
+	}
+	return m
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// Reports whether the space-separated list of tokens, e.g. the rel of a link, has the token.
+func hasToken(list, token string) bool {
+	for _, t := range strings.Fields(list) {
+		if strings.EqualFold(t, token) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the text of the node and its descendants, with whitespace collapsed.
+func nodeText(n *html.Node) string {
+	var b strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+			b.WriteByte(' ')
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// Resolves the link against the base URL without its fragment, empty if it cannot be parsed.
+func resolveLink(base *url.URL, link string) string {
+	link = strings.TrimSpace(link)
+	if link == "" {
+		return ""
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	u = base.ResolveReference(u)
+	u.Fragment = ""
+	return u.String()
+}
+
+// Reports whether the Content-Type is HTML, which is the only media type with page metadata.
+func isHTML(contentType string) bool {
+	mt := mediaType(contentType)
+	return mt == "" || mt == "text/html" || mt == "application/xhtml+xml"
+}
+
+// Extracts the metadata of an HTML page, and the directives of the X-Robots-Tag header of a page
+// of any media type, so that PDFs, feeds and JSON can be noindex or nofollow as well. Returns nil
+// if page metadata is not extracted, or if a page that is not HTML has no robots directives.
+func (c *Client) parsePageMeta(currLink string, text []byte, header http.Header) *PageMeta {
+	if !c.pageMeta {
+		return nil
+	}
+	htmlPage := isHTML(header.Get("Content-Type"))
+	m := &PageMeta{}
+	if base, err := url.Parse(currLink); err == nil && htmlPage {
+		if parsed := ExtractPageMeta(base, bytes.NewReader(text)); parsed != nil {
+			m = parsed
+		}
+	}
+	m.addRobotsHeader(header)
+	if !htmlPage && len(m.Robots) == 0 {
+		return nil
+	}
+	return m
+}
+
+// Adds the canonical URL of the page to its links if it is another page, so that the
+// canonical version is crawled as well.
+func withCanonical(links []string, currLink string, m *PageMeta) []string {
+	if m.IsCanonical(currLink) || slices.Contains(links, m.Canonical) {
+		return links
+	}
+	return append(links, m.Canonical)
+}
@@ -0,0 +1,162 @@
+package gocrawler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExtractPageMeta(t *testing.T) {
+	body := `<html lang=" en-GB "><head>
+		<title> A   page </title>
+		<meta name="description" content=" About a page ">
+		<meta name="description" content="second">
+		<meta name="ROBOTS" content="NoIndex, follow">
+		<meta property="og:title" content="OG title">
+		<meta property="og:title" content="second">
+		<meta name="twitter:card" content="summary">
+		<link rel="alternate canonical" href="/canonical#top">
+		<script type="application/ld+json"> {"@type": "Article",
+			"name": "A page"} </script>
+		<script type="application/ld+json">{invalid</script>
+		<script>var title = "<h1>not a heading</h1>";</script>
+	</head><body>
+		<svg><title>icon</title></svg>
+		<h1>Heading <em>one</em></h1><h3>Three</h3><h2> </h2>
+	</body></html>`
+	base, _ := url.Parse("https://a.com/page")
+
+	exp := &PageMeta{
+		Title:       "A page",
+		Description: "About a page",
+		Lang:        "en-GB",
+		Canonical:   "https://a.com/canonical",
+		Robots:      []string{"noindex", "follow"},
+		Headings:    []Heading{{Level: 1, Text: "Heading one"}, {Level: 3, Text: "Three"}},
+		OpenGraph:   map[string]string{"og:title": "OG title"},
+		Twitter:     map[string]string{"twitter:card": "summary"},
+		JSONLD:      []json.RawMessage{json.RawMessage(`{"@type":"Article","name":"A page"}`)},
+	}
+	got := ExtractPageMeta(base, strings.NewReader(body))
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %+v, got %+v", exp, got)
+	}
+	if !got.NoIndex() || got.NoFollow() || got.Indexable("https://a.com/page") {
+		t.Errorf("Expected the page to be noindex but not nofollow, got %v", got.Robots)
+	}
+}
+
+func TestAddRobotsHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		exp    []string
+	}{
+		{"all crawlers", []string{"NoIndex, NoFollow"}, []string{"noindex", "nofollow"}},
+		{"repeated", []string{"noindex", "noindex, noarchive"}, []string{"noindex", "noarchive"}},
+		{"crawler specific", []string{"googlebot: noindex", "otherbot: nofollow, noarchive"}, nil},
+		{"unavailable_after", []string{"unavailable_after: 25 Jun 2010 15:00:00 PST"}, []string{"unavailable_after: 25 jun 2010 15:00:00 pst"}},
+		{"directive before unavailable_after", []string{"noindex, unavailable_after: 2010-06-25"}, []string{"noindex", "unavailable_after: 2010-06-25"}},
+	}
+	for _, tt := range tests {
+		header := make(http.Header)
+		for _, v := range tt.values {
+			header.Add("X-Robots-Tag", v)
+		}
+		m := &PageMeta{}
+		m.addRobotsHeader(header)
+		if !reflect.DeepEqual(m.Robots, tt.exp) {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.exp, m.Robots)
+		}
+	}
+}
+
+func TestWithCanonical(t *testing.T) {
+	const link = "https://a.com/page?sort=asc"
+	tests := []struct {
+		name  string
+		links []string
+		meta  *PageMeta
+		exp   []string
+	}{
+		{"no page meta", []string{"https://a.com/b"}, nil, []string{"https://a.com/b"}},
+		{"no canonical", []string{"https://a.com/b"}, &PageMeta{}, []string{"https://a.com/b"}},
+		{"canonical is itself", []string{"https://a.com/b"}, &PageMeta{Canonical: link}, []string{"https://a.com/b"}},
+		{"canonical is linked", []string{"https://a.com/page"}, &PageMeta{Canonical: "https://a.com/page"}, []string{"https://a.com/page"}},
+		{"canonical is another page", []string{"https://a.com/b"}, &PageMeta{Canonical: "https://a.com/page"}, []string{"https://a.com/b", "https://a.com/page"}},
+	}
+	for _, tt := range tests {
+		if got := withCanonical(tt.links, link, tt.meta); !reflect.DeepEqual(got, tt.exp) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.exp, got)
+		}
+	}
+}
+
+func TestParsePageMeta(t *testing.T) {
+	c := &Client{pageMeta: true}
+	header := http.Header{"Content-Type": {"application/pdf"}, "X-Robots-Tag": {"noindex"}}
+	if m := c.parsePageMeta("https://a.com/a.pdf", []byte("%PDF-1.7"), header); m == nil || !m.NoIndex() {
+		t.Errorf("Expected the X-Robots-Tag of a pdf to be noindex, got %+v", m)
+	}
+	header.Del("X-Robots-Tag")
+	if m := c.parsePageMeta("https://a.com/a.pdf", []byte("%PDF-1.7"), header); m != nil {
+		t.Errorf("Expected no page meta for a pdf without X-Robots-Tag, got %+v", m)
+	}
+	header = http.Header{"Content-Type": {"text/html"}, "X-Robots-Tag": {"nofollow"}}
+	m := c.parsePageMeta("https://a.com/", []byte(`<title>A</title><meta name="robots" content="noindex">`), header)
+	if exp := []string{"noindex", "nofollow"}; m == nil || m.Title != "A" || !reflect.DeepEqual(m.Robots, exp) {
+		t.Errorf("Expected the title and robots %v, got %+v", exp, m)
+	}
+
+	c.pageMeta = false
+	if m := c.parsePageMeta("https://a.com/", []byte(`<title>A</title>`), header); m != nil {
+		t.Errorf("Expected no page meta when it is not extracted, got %+v", m)
+	}
+}
+
+func TestNoFollowStopsLinks(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		robots      string
+		body        string
+		exp         []string
+	}{
+		{"html", "text/html", "", `<a href="/b">B</a>`, []string{"https://a.com/b"}},
+		{"html meta nofollow", "text/html", "", `<meta name="robots" content="nofollow"><a href="/b">B</a>`, nil},
+		{"html header nofollow", "text/html", "none", `<a href="/b">B</a>`, nil},
+		{"header nofollow for another crawler", "text/html", "otherbot: nofollow", `<a href="/b">B</a>`, []string{"https://a.com/b"}},
+		{"json header nofollow", "application/json", "nofollow", `{"next": "/b"}`, nil},
+		{"feed header nofollow", "application/rss+xml", "nofollow", `<rss><channel><link>https://a.com/b</link></channel></rss>`, nil},
+		{"text", "text/plain", "", `see https://a.com/b`, []string{"https://a.com/b"}},
+		{"text header nofollow", "text/plain", "nofollow", `see https://a.com/b`, nil},
+	}
+	for _, tt := range tests {
+		c := New(context.Background(), &Config{
+			MaxRetries:      1,
+			Timeout:         5 * time.Second,
+			ProxyURL:        &url.URL{},
+			ExtractPageMeta: true,
+			ContentHandlers: DefaultContentHandlers(),
+		}, nil, DefaultLinkExtractor)
+		header := http.Header{"Content-Type": {tt.contentType}}
+		if tt.robots != "" {
+			header.Set("X-Robots-Tag", tt.robots)
+		}
+
+		got := c.updatePageInfo(0, "https://a.com/", "", []byte(tt.body), false, nil, header, time.Now(), TransferInfo{})
+		if !reflect.DeepEqual(got, tt.exp) {
+			t.Errorf("%s: expected the links %v to be followed, got %v", tt.name, tt.exp, got)
+		}
+		// links that are not followed are recorded as disallowed by the robots directives
+		ai, ok := c.Attempts["https://a.com/b"]
+		if disallowed := ok && ai.Outcome == OutcomeRobotsDisallowed; disallowed != (tt.exp == nil) {
+			t.Errorf("%s: expected the link to be recorded as disallowed: %v, got %+v", tt.name, tt.exp == nil, ai)
+		}
+	}
+}
@@ -0,0 +1,418 @@
+// Package readability extracts the main text of HTML pages, such as the body of an article,
+// without the boilerplate around it (navigation, headers, footers, sidebars, ads, scripts and
+// forms), in the manner of the Readability algorithm of browsers' reader modes.
+package readability
+
+import (
+	"bytes"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// WordsPerMinute is the average silent reading speed of adults that reading times are
+// estimated with.
+const WordsPerMinute = 238
+
+// min length of the text of a paragraph for it to be scored
+const minParagraphLength = 25
+
+var (
+	// class names and IDs of elements that are unlikely to be part of the main text, unless
+	// they are also likely to be
+	unlikelyRegex = regexp.MustCompile(`(?i)(^|[\s_-])(ads?|adv|advert|advertisement|banner|breadcrumbs?|comments?|cookie|disqus|footer|footnote|gdpr|header|masthead|menu|modal|nav|navbar|pager|pagination|popup|promo|related|share|sharing|sidebar|social|sponsor|sponsored|subscribe|widget)($|[\s_-])`)
+	likelyRegex   = regexp.MustCompile(`(?i)(^|[\s_-])(article|body|content|entry|hentry|h-entry|main|post|story|text)($|[\s_-])`)
+
+	// roles of elements that are not part of the main text
+	unlikelyRoles = map[string]struct{}{
+		"banner": {}, "complementary": {}, "contentinfo": {}, "dialog": {}, "menu": {},
+		"menubar": {}, "navigation": {}, "search": {}, "toolbar": {},
+	}
+)
+
+// elements that are removed before scoring, along with their contents
+var boilerplateTags = map[atom.Atom]struct{}{
+	atom.Script: {}, atom.Style: {}, atom.Noscript: {}, atom.Template: {}, atom.Iframe: {},
+	atom.Form: {}, atom.Button: {}, atom.Input: {}, atom.Select: {}, atom.Textarea: {},
+	atom.Nav: {}, atom.Footer: {}, atom.Aside: {}, atom.Svg: {}, atom.Canvas: {},
+	atom.Object: {}, atom.Embed: {}, atom.Dialog: {}, atom.Head: {},
+}
+
+// elements whose text is a block of its own in the extracted text
+var blockTags = map[atom.Atom]struct{}{
+	atom.Address: {}, atom.Article: {}, atom.Blockquote: {}, atom.Dd: {}, atom.Div: {},
+	atom.Dl: {}, atom.Dt: {}, atom.Figcaption: {}, atom.Figure: {}, atom.H1: {}, atom.H2: {},
+	atom.H3: {}, atom.H4: {}, atom.H5: {}, atom.H6: {}, atom.Header: {}, atom.Hr: {},
+	atom.Li: {}, atom.Main: {}, atom.Ol: {}, atom.P: {}, atom.Pre: {}, atom.Section: {},
+	atom.Table: {}, atom.Td: {}, atom.Th: {}, atom.Tr: {}, atom.Ul: {}, atom.Br: {},
+}
+
+// Article is the main text of a page, paragraphs are separated by blank lines.
+type Article struct {
+	Title          string `json:"title,omitempty"`
+	Text           string `json:"text"`
+	WordCount      int    `json:"word_count"`
+	ReadingSeconds int    `json:"reading_seconds"`
+}
+
+// ReadingTime returns the estimated time to read the article.
+func (a Article) ReadingTime() time.Duration {
+	return time.Duration(a.ReadingSeconds) * time.Second
+}
+
+// ReadingTime estimates the time to read a text with the number of words at WordsPerMinute,
+// rounded up to the second.
+func ReadingTime(words int) time.Duration {
+	return time.Duration(math.Ceil(float64(words)*60/WordsPerMinute)) * time.Second
+}
+
+// Extract returns the main text of the HTML. Paragraphs are scored by their length and number
+// of commas, and the scores are added to their parent and, halved, to their grandparent. The
+// element with the highest score, discounted by how much of its text is links, is the main
+// content, together with its siblings that score close to it. Pages without paragraphs, e.g.
+// lists of links, fall back to the whole body without boilerplate.
+func Extract(body []byte) Article {
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return Article{}
+	}
+
+	title := findTitle(doc)
+	removeBoilerplate(doc)
+
+	root := findBody(doc)
+	content := []*html.Node{root}
+	if scores := scoreParagraphs(root); len(scores) > 0 {
+		content = withSiblings(topCandidate(root, scores), scores)
+	}
+
+	var blocks []string
+	for _, n := range content {
+		blocks = appendBlocks(blocks, n)
+	}
+	text := strings.Join(blocks, "\n\n")
+	words := len(strings.Fields(text))
+	return Article{
+		Title:          title,
+		Text:           text,
+		WordCount:      words,
+		ReadingSeconds: int(ReadingTime(words) / time.Second),
+	}
+}
+
+// Returns the <title> of the page, or the first <h1> if it has none.
+func findTitle(doc *html.Node) string {
+	var title, h1 string
+	walk(doc, func(n *html.Node) bool {
+		switch n.DataAtom {
+		case atom.Svg:
+			return false
+		case atom.Title:
+			if title == "" {
+				title = textOf(n)
+			}
+		case atom.H1:
+			if h1 == "" {
+				h1 = textOf(n)
+			}
+		}
+		return true
+	})
+	if title == "" {
+		return h1
+	}
+	return title
+}
+
+// Removes the elements that are not part of the main text by their tag, ARIA role, being
+// hidden, or their class name or ID.
+func removeBoilerplate(doc *html.Node) {
+	var remove []*html.Node
+	walk(doc, func(n *html.Node) bool {
+		if isBoilerplate(n) {
+			remove = append(remove, n)
+			return false
+		}
+		return true
+	})
+	for _, n := range remove {
+		n.Parent.RemoveChild(n)
+	}
+}
+
+func isBoilerplate(n *html.Node) bool {
+	if _, ok := boilerplateTags[n.DataAtom]; ok {
+		return true
+	}
+	switch n.DataAtom {
+	case atom.Html, atom.Body, atom.Article, atom.Main:
+		return false
+	}
+	if _, ok := unlikelyRoles[strings.ToLower(attr(n, "role"))]; ok {
+		return true
+	}
+	if hasAttr(n, "hidden") || attr(n, "aria-hidden") == "true" {
+		return true
+	}
+	if style := strings.ReplaceAll(strings.ToLower(attr(n, "style")), " ", ""); strings.Contains(style, "display:none") {
+		return true
+	}
+	classAndID := attr(n, "class") + " " + attr(n, "id")
+	return unlikelyRegex.MatchString(classAndID) && !likelyRegex.MatchString(classAndID)
+}
+
+func findBody(doc *html.Node) *html.Node {
+	body := doc
+	walk(doc, func(n *html.Node) bool {
+		if n.DataAtom == atom.Body {
+			body = n
+			return false
+		}
+		return true
+	})
+	return body
+}
+
+// Scores the paragraphs under the root, returning the scores of their parents and
+// grandparents discounted by their link density.
+func scoreParagraphs(root *html.Node) map[*html.Node]float64 {
+	scores := make(map[*html.Node]float64)
+	addScore := func(n *html.Node, score float64) {
+		if n == nil || n.Type != html.ElementNode {
+			return
+		}
+		if _, ok := scores[n]; !ok {
+			scores[n] = initialScore(n)
+		}
+		scores[n] += score
+	}
+
+	walk(root, func(n *html.Node) bool {
+		switch n.DataAtom {
+		case atom.P, atom.Pre, atom.Td, atom.Blockquote:
+		default:
+			return true
+		}
+		text := textOf(n)
+		if len(text) < minParagraphLength {
+			return false
+		}
+		// a point for the paragraph, for each comma, and for each 100 characters up to 3
+		score := 1 + float64(strings.Count(text, ",")) + min(float64(len(text)/100), 3)
+		addScore(n.Parent, score)
+		if n.Parent != nil {
+			addScore(n.Parent.Parent, score/2)
+		}
+		return false
+	})
+
+	for n, score := range scores {
+		scores[n] = score * (1 - linkDensity(n))
+	}
+	return scores
+}
+
+// Returns the element with the highest score, ties are broken by the order in the document so
+// that the result does not depend on map iteration order.
+func topCandidate(root *html.Node, scores map[*html.Node]float64) *html.Node {
+	var (
+		top      *html.Node
+		topScore float64
+	)
+	walk(root, func(n *html.Node) bool {
+		if score, ok := scores[n]; ok && (top == nil || score > topScore) {
+			top, topScore = n, score
+		}
+		return true
+	})
+
+	// the only child of an element has the same text as it, so the parent is used to include
+	// its siblings
+	for top.Parent != nil && top.Parent != root && onlyElementChild(top.Parent) == top {
+		scores[top.Parent] = max(scores[top.Parent], scores[top])
+		top = top.Parent
+	}
+	return top
+}
+
+// Returns the top candidate and its siblings that are likely to be part of the main text as
+// well, such as the introduction of an article in a separate element. Siblings are included if
+// they score at least a fifth of the top candidate, or if they are paragraphs of text with few
+// links.
+func withSiblings(top *html.Node, scores map[*html.Node]float64) []*html.Node {
+	if top.Parent == nil {
+		return []*html.Node{top}
+	}
+
+	threshold := max(10, scores[top]*0.2)
+	var nodes []*html.Node
+	for sib := top.Parent.FirstChild; sib != nil; sib = sib.NextSibling {
+		if sib == top {
+			nodes = append(nodes, sib)
+			continue
+		}
+		if sib.Type != html.ElementNode {
+			continue
+		}
+		if score, ok := scores[sib]; ok && score >= threshold {
+			nodes = append(nodes, sib)
+			continue
+		}
+		if sib.DataAtom == atom.P {
+			text := textOf(sib)
+			density := linkDensity(sib)
+			if (len(text) > 80 && density < 0.25) || (len(text) > 0 && density == 0 && strings.Contains(text, ". ")) {
+				nodes = append(nodes, sib)
+			}
+		}
+	}
+	return nodes
+}
+
+// Scores an element by its tag and by its class name and ID.
+func initialScore(n *html.Node) float64 {
+	var score float64
+	switch n.DataAtom {
+	case atom.Article, atom.Main:
+		score = 10
+	case atom.Div:
+		score = 5
+	case atom.Pre, atom.Td, atom.Blockquote:
+		score = 3
+	case atom.Address, atom.Ol, atom.Ul, atom.Dl, atom.Dd, atom.Dt, atom.Li:
+		score = -3
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Th:
+		score = -5
+	}
+
+	classAndID := attr(n, "class") + " " + attr(n, "id")
+	if likelyRegex.MatchString(classAndID) {
+		score += 25
+	}
+	if unlikelyRegex.MatchString(classAndID) {
+		score -= 25
+	}
+	return score
+}
+
+// Returns how much of the text of the element is the text of links, between 0 and 1.
+func linkDensity(n *html.Node) float64 {
+	textLength := len(textOf(n))
+	if textLength == 0 {
+		return 0
+	}
+	var linkLength int
+	walk(n, func(c *html.Node) bool {
+		if c.DataAtom == atom.A {
+			linkLength += len(textOf(c))
+			return false
+		}
+		return true
+	})
+	return float64(linkLength) / float64(textLength)
+}
+
+func onlyElementChild(n *html.Node) *html.Node {
+	var only *html.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch c.Type {
+		case html.ElementNode:
+			if only != nil {
+				return nil
+			}
+			only = c
+		case html.TextNode:
+			if strings.TrimSpace(c.Data) != "" {
+				return nil
+			}
+		}
+	}
+	return only
+}
+
+// Appends the text of the element as blocks, one for each block-level element, with
+// whitespace collapsed within blocks.
+func appendBlocks(blocks []string, n *html.Node) []string {
+	var b strings.Builder
+	flush := func() {
+		if text := strings.Join(strings.Fields(b.String()), " "); text != "" {
+			blocks = append(blocks, text)
+		}
+		b.Reset()
+	}
+
+	var visit func(n *html.Node)
+	visit = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			b.WriteString(n.Data)
+			return
+		case html.ElementNode, html.DocumentNode:
+		default:
+			return
+		}
+		_, isBlock := blockTags[n.DataAtom]
+		if isBlock {
+			flush()
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			visit(c)
+		}
+		if isBlock {
+			flush()
+		}
+	}
+	visit(n)
+	flush()
+	return blocks
+}
+
+// Calls fn for the node and its descendants in document order, the descendants of a node are
+// skipped if fn returns false.
+func walk(n *html.Node, fn func(n *html.Node) bool) {
+	if n.Type == html.ElementNode && !fn(n) {
+		return
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		walk(c, fn)
+	}
+}
+
+// Returns the text of the node and its descendants, with whitespace collapsed.
+func textOf(n *html.Node) string {
+	var b strings.Builder
+	var visit func(n *html.Node)
+	visit = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+			b.WriteByte(' ')
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			visit(c)
+		}
+	}
+	visit(n)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package readability_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yusufaine/gocrawler/readability"
+)
+
+func TestExtract(t *testing.T) {
+	body := `<html><head><title>My Post</title><script>var x = "<p>no</p>";</script></head><body>
+<header class="site-header"><a href="/">Home</a> <a href="/about">About</a></header>
+<nav><ul><li><a href="/a">A</a></li><li><a href="/b">B</a></li></ul></nav>
+<div id="main-wrapper"><div class="sidebar"><p>Subscribe to our newsletter, it is great, really great, honestly.</p></div>
+<article class="post"><h1>The title of the post</h1>
+<p>This is the first paragraph of the article, which has a fair amount of text, commas, and so on.</p>
+<p>The second paragraph continues the story. It has a <a href="/x">link</a> in it, but mostly text.</p>
+<div class="ad-banner">Buy now!</div>
+<p style="display: none">Hidden text that should not be read by anyone.</p>
+<p>A third paragraph ends it all, with yet more words to count, and another comma.</p>
+</article>
+<div class="comments"><p>Great post, thanks for writing it, I learnt a lot from it today.</p></div></div>
+<footer>Copyright 2023, all rights reserved, everywhere in the universe.</footer></body></html>`
+
+	a := readability.Extract([]byte(body))
+	exp := strings.Join([]string{
+		"The title of the post",
+		"This is the first paragraph of the article, which has a fair amount of text, commas, and so on.",
+		"The second paragraph continues the story. It has a link in it, but mostly text.",
+		"A third paragraph ends it all, with yet more words to count, and another comma.",
+	}, "\n\n")
+	if a.Text != exp {
+		t.Errorf("Expected main text:\n%s\ngot:\n%s", exp, a.Text)
+	}
+	if a.Title != "My Post" {
+		t.Errorf("Expected title My Post, got %q", a.Title)
+	}
+	if a.WordCount != len(strings.Fields(exp)) {
+		t.Errorf("Expected %d words, got %d", len(strings.Fields(exp)), a.WordCount)
+	}
+	if a.ReadingTime() != readability.ReadingTime(a.WordCount) || a.ReadingSeconds == 0 {
+		t.Errorf("Expected reading time of %d words, got %v", a.WordCount, a.ReadingTime())
+	}
+}
+
+func TestExtractWithoutParagraphs(t *testing.T) {
+	a := readability.Extract([]byte(`<nav><a href="/">Home</a></nav><ul><li><a href="/a">Alpha</a></li><li><a href="/b">Beta</a></li></ul>`))
+	if a.Text != "Alpha\n\nBeta" {
+		t.Errorf("Expected the body without boilerplate, got %q", a.Text)
+	}
+}
+
+func TestReadingTime(t *testing.T) {
+	if got := readability.ReadingTime(readability.WordsPerMinute); got != time.Minute {
+		t.Errorf("Expected %d words to take a minute, got %v", readability.WordsPerMinute, got)
+	}
+	if got := readability.ReadingTime(0); got != 0 {
+		t.Errorf("Expected no words to take no time, got %v", got)
+	}
+}
@@ -0,0 +1,35 @@
+package gocrawler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// Exchange is a single HTTP request and response made by the crawler, including the responses
+// of redirects that were followed. The request can be found in Response.Request.
+type Exchange struct {
+	Response  *http.Response
+	Body      []byte    // body that was read, empty if the body was not read
+	Truncated bool      // set if Body is not the full body of the response
+	FetchedAt time.Time // when the request was sent
+	Depth     int
+	Parent    string
+	Links     []string // outgoing links extracted from the body, if any
+}
+
+// ExchangeRecorder is notified of every HTTP exchange made while crawling, which can be used
+// to archive the crawl (e.g. warc.Writer). Implementations must be safe for concurrent use.
+type ExchangeRecorder interface {
+	RecordExchange(ex *Exchange) error
+}
+
+func (c *Client) recordExchange(ex *Exchange) {
+	if c.recorder == nil {
+		return
+	}
+	if err := c.recorder.RecordExchange(ex); err != nil {
+		log.Error("unable to record exchange", "url", ex.Response.Request.URL, "error", err)
+	}
+}
@@ -0,0 +1,107 @@
+package gocrawler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const defaultMaxRedirects = 10
+
+var (
+	ErrBlacklistedRedirect = errors.New("redirect to blacklisted host")
+	ErrCrossHostRedirect   = errors.New("redirect to a different host")
+	ErrRedirectLoop        = errors.New("redirect loop")
+	ErrTooManyRedirects    = errors.New("too many redirects")
+)
+
+// Sent to the underlying http.Client so that redirects are returned to the crawler instead of
+// being followed silently.
+func noFollowRedirect(req *http.Request, via []*http.Request) error {
+	return http.ErrUseLastResponse
+}
+
+func isRedirect(statusCode int) bool {
+	switch statusCode {
+	case http.StatusMovedPermanently,
+		http.StatusFound,
+		http.StatusSeeOther,
+		http.StatusTemporaryRedirect,
+		http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// Performs the GET request for the link and follows any redirects manually so that each hop
+// can be recorded and checked against the blacklist and the redirect policy. The hops taken
+// are returned even if the chain was cut short, and the response is that of the final hop.
+// Every request waits for the rate limiter, including the request of each hop.
+func (c *Client) fetch(link *url.URL, parent string, depth int) (*http.Response, []RedirectHop, error) {
+	var (
+		hops    []RedirectHop
+		currURL = link
+		seen    = map[string]struct{}{link.String(): {}}
+	)
+	for {
+		// ensure RPS is enforced
+		_ = c.rl.Wait(c.ctx)
+		req, err := http.NewRequestWithContext(c.ctx, "GET", currURL.String(), nil)
+		if err != nil {
+			return nil, hops, err
+		}
+		c.addConditionalHeaders(req)
+
+		fetchedAt := time.Now()
+		resp, err := c.hc.Do(req)
+		if err != nil {
+			return nil, hops, err
+		}
+
+		location := resp.Header.Get("Location")
+		if c.maxRedirects < 0 || !isRedirect(resp.StatusCode) || location == "" {
+			return resp, hops, nil
+		}
+
+		// the body of a redirect is not used, drain it so that the connection can be reused
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		truncated, _ := io.Copy(io.Discard, io.LimitReader(resp.Body, 1))
+		resp.Body.Close()
+		c.recordExchange(&Exchange{
+			Response:  resp,
+			Body:      body,
+			Truncated: truncated > 0,
+			FetchedAt: fetchedAt,
+			Depth:     depth,
+			Parent:    parent,
+		})
+
+		nextURL, err := currURL.Parse(location)
+		if err != nil {
+			return nil, hops, err
+		}
+		hops = append(hops, RedirectHop{
+			URL:        currURL.String(),
+			StatusCode: resp.StatusCode,
+			Location:   nextURL.String(),
+		})
+
+		if len(hops) > c.maxRedirects {
+			return nil, hops, ErrTooManyRedirects
+		}
+		if _, ok := seen[nextURL.String()]; ok {
+			return nil, hops, ErrRedirectLoop
+		}
+		if c.sameHostRedirects && nextURL.Host != link.Host {
+			return nil, hops, ErrCrossHostRedirect
+		}
+		if !c.isHostAllowed(nextURL.Host) {
+			return nil, hops, ErrBlacklistedRedirect
+		}
+
+		seen[nextURL.String()] = struct{}{}
+		currURL = nextURL
+	}
+}
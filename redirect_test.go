@@ -0,0 +1,132 @@
+package gocrawler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/yusufaine/gocrawler/hostrule"
+)
+
+func newRedirectServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/a", func(w http.ResponseWriter, r *http.Request) { http.Redirect(w, r, "/b", http.StatusMovedPermanently) })
+	mux.HandleFunc("/b", func(w http.ResponseWriter, r *http.Request) { http.Redirect(w, r, "c", http.StatusFound) })
+	mux.HandleFunc("/c", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("c")) })
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) { http.Redirect(w, r, "/loop2", http.StatusFound) })
+	mux.HandleFunc("/loop2", func(w http.ResponseWriter, r *http.Request) { http.Redirect(w, r, "/loop", http.StatusFound) })
+	mux.HandleFunc("/offsite", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://blocked.example/", http.StatusTemporaryRedirect)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newRedirectClient(config Config) *Client {
+	config.MaxRetries = 1
+	config.Timeout = 5 * time.Second
+	config.ProxyURL = &url.URL{}
+	return New(context.Background(), &config, nil, nil)
+}
+
+func fetchPath(t *testing.T, c *Client, srv *httptest.Server, path string) (*http.Response, []RedirectHop, error) {
+	t.Helper()
+	u, err := url.Parse(srv.URL + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, hops, err := c.fetch(u, "", 0)
+	if resp != nil {
+		resp.Body.Close()
+	}
+	return resp, hops, err
+}
+
+func TestFetchRedirectChain(t *testing.T) {
+	srv := newRedirectServer(t)
+	resp, hops, err := fetchPath(t, newRedirectClient(Config{}), srv, "/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []RedirectHop{
+		{URL: srv.URL + "/a", StatusCode: http.StatusMovedPermanently, Location: srv.URL + "/b"},
+		{URL: srv.URL + "/b", StatusCode: http.StatusFound, Location: srv.URL + "/c"},
+	}
+	if !reflect.DeepEqual(hops, exp) {
+		t.Errorf("Expected hops %v, got %v", exp, hops)
+	}
+	if got := resp.Request.URL.String(); got != srv.URL+"/c" {
+		t.Errorf("Expected final URL %s, got %s", srv.URL+"/c", got)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestFetchRedirectRateLimit(t *testing.T) {
+	// each of the 3 requests of the chain waits for the rate limiter, the first one does not
+	// have to wait as the limiter starts with a token
+	srv := newRedirectServer(t)
+	start := time.Now()
+	if _, _, err := fetchPath(t, newRedirectClient(Config{MaxRPS: 10}), srv, "/a"); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("Expected the redirect hops to be rate limited, took %v", elapsed)
+	}
+}
+
+func TestFetchRedirectDisabled(t *testing.T) {
+	srv := newRedirectServer(t)
+	resp, hops, err := fetchPath(t, newRedirectClient(Config{MaxRedirects: -1}), srv, "/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hops) != 0 {
+		t.Errorf("Expected no hops, got %v", hops)
+	}
+	if resp.StatusCode != http.StatusMovedPermanently {
+		t.Errorf("Expected status %d, got %d", http.StatusMovedPermanently, resp.StatusCode)
+	}
+}
+
+func TestFetchRedirectPolicy(t *testing.T) {
+	blacklist, err := hostrule.ParseRules("blocked.example")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		config Config
+		path   string
+		hops   int
+		exp    error
+	}{
+		{"loop", Config{}, "/loop", 2, ErrRedirectLoop},
+		{"too many", Config{MaxRedirects: 1}, "/a", 2, ErrTooManyRedirects},
+		{"cross host", Config{SameHostRedirects: true}, "/offsite", 1, ErrCrossHostRedirect},
+		{"blacklisted", Config{BlacklistHosts: blacklist}, "/offsite", 1, ErrBlacklistedRedirect},
+	}
+	srv := newRedirectServer(t)
+	for _, tt := range tests {
+		resp, hops, err := fetchPath(t, newRedirectClient(tt.config), srv, tt.path)
+		if !errors.Is(err, tt.exp) {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.exp, err)
+		}
+		if resp != nil {
+			t.Errorf("%s: expected no response, got status %d", tt.name, resp.StatusCode)
+		}
+		if len(hops) != tt.hops {
+			t.Errorf("%s: expected %d hops, got %v", tt.name, tt.hops, hops)
+		}
+	}
+}
@@ -1,14 +1,128 @@
 package gocrawler
 
 import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
 	"strings"
 )
 
 // ResponseMatcher is a function that takes an http.Response and returns a boolean to indicate
-// whether or not the contents of the URL should be processed (e.g extract links)
+// whether or not the contents of the URL should be processed (e.g extract links). Rejections are
+// reported by the name of the function, use Named for function literals.
 type ResponseMatcher func(resp *http.Response) bool
 
+// MatchResponse reports whether the response matches, and if it does not, the name of the
+// function.
+func (rm ResponseMatcher) MatchResponse(resp *http.Response) (bool, string) {
+	if rm(resp) {
+		return true, ""
+	}
+	return false, funcName(rm)
+}
+
+// Returns the function name of the matcher without its package path, e.g. "IsHtmlContent".
+func funcName(rm ResponseMatcher) string {
+	name := runtime.FuncForPC(reflect.ValueOf(rm).Pointer()).Name()
+	name = name[strings.LastIndex(name, "/")+1:]
+	if i := strings.Index(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// Matcher decides whether or not the contents of a response should be processed, and reports
+// the name of the matcher that rejected it, which is how rejected responses are recorded in
+// AttemptInfo.Matcher. It is implemented by ResponseMatcher and NamedMatcher, and matchers can
+// be combined with And, Or and Not.
+type Matcher interface {
+	MatchResponse(resp *http.Response) (ok bool, rejectedBy string)
+}
+
+// NamedMatcher is a matcher with a name, as returned by Named and the combinators.
+type NamedMatcher struct {
+	Name  string
+	Match func(resp *http.Response) (ok bool, rejectedBy string)
+}
+
+func (m NamedMatcher) MatchResponse(resp *http.Response) (bool, string) {
+	return m.Match(resp)
+}
+
+// MatcherName returns the name of the matcher, the Name of a NamedMatcher or the function name
+// of a ResponseMatcher without its package path, e.g. "IsHtmlContent".
+func MatcherName(m Matcher) string {
+	switch m := m.(type) {
+	case NamedMatcher:
+		return m.Name
+	case ResponseMatcher:
+		return funcName(m)
+	}
+	return fmt.Sprintf("%T", m)
+}
+
+// Named returns the matcher with the name, which is reported instead of the names of the
+// matchers it is made of.
+func Named(name string, rm ResponseMatcher) NamedMatcher {
+	return NamedMatcher{Name: name, Match: func(resp *http.Response) (bool, string) {
+		if rm(resp) {
+			return true, ""
+		}
+		return false, name
+	}}
+}
+
+// And matches responses that match all of the matchers, which are checked in order. Rejections
+// are reported by the first matcher that failed.
+func And(ms ...Matcher) NamedMatcher {
+	return NamedMatcher{Name: "And(" + joinMatchers(ms) + ")", Match: func(resp *http.Response) (bool, string) {
+		for _, m := range ms {
+			if ok, rejectedBy := m.MatchResponse(resp); !ok {
+				return false, rejectedBy
+			}
+		}
+		return true, ""
+	}}
+}
+
+// Or matches responses that match any of the matchers, which are checked in order. Rejections
+// are reported as Or(...) with the names of all the matchers.
+func Or(ms ...Matcher) NamedMatcher {
+	name := "Or(" + joinMatchers(ms) + ")"
+	return NamedMatcher{Name: name, Match: func(resp *http.Response) (bool, string) {
+		for _, m := range ms {
+			if ok, _ := m.MatchResponse(resp); ok {
+				return true, ""
+			}
+		}
+		return false, name
+	}}
+}
+
+// Not matches responses that do not match the matcher.
+func Not(m Matcher) NamedMatcher {
+	name := "Not(" + MatcherName(m) + ")"
+	return NamedMatcher{Name: name, Match: func(resp *http.Response) (bool, string) {
+		if ok, _ := m.MatchResponse(resp); ok {
+			return false, name
+		}
+		return true, ""
+	}}
+}
+
+func joinMatchers(ms []Matcher) string {
+	names := make([]string, len(ms))
+	for i, m := range ms {
+		names[i] = MatcherName(m)
+	}
+	return strings.Join(names, ", ")
+}
+
 // This matches all responses
 func IsNoopResponse(resp *http.Response) bool {
 	return true
@@ -29,8 +143,95 @@ func IsServerErrorResponse(resp *http.Response) bool {
 	return resp.StatusCode >= 500
 }
 
-// This matches all responses that return a 2xx status code and have a Content-Type header
-// that contains "text/html"
+// This matches all responses that return a 200 status code and have a "text/html" Content-Type
 func IsHtmlContent(resp *http.Response) bool {
-	return IsOkResponse(resp) && strings.Contains(resp.Header.Get("Content-Type"), "text/html")
+	return IsOkResponse(resp) && hasMediaType(resp.Header.Get("Content-Type"), []string{"text/html"})
+}
+
+// StatusBetween matches responses with a status code between lo and hi, inclusive.
+func StatusBetween(lo, hi int) NamedMatcher {
+	return Named(fmt.Sprintf("StatusBetween(%d, %d)", lo, hi), func(resp *http.Response) bool {
+		return resp.StatusCode >= lo && resp.StatusCode <= hi
+	})
+}
+
+// MIMEType matches responses whose Content-Type is any of the media types, ignoring parameters
+// such as the charset. A media type can be a family, e.g. "image/*", or "*/*" for any type.
+func MIMEType(types ...string) NamedMatcher {
+	return Named("MIMEType("+strings.Join(types, ", ")+")", func(resp *http.Response) bool {
+		return hasMediaType(resp.Header.Get("Content-Type"), types)
+	})
+}
+
+// SniffedType matches responses whose content, sniffed from the first 512 bytes of the body
+// with http.DetectContentType, is any of the media types as with MIMEType. This is useful for
+// servers that send the wrong Content-Type. The body is still read in full afterwards.
+func SniffedType(types ...string) NamedMatcher {
+	return Named("SniffedType("+strings.Join(types, ", ")+")", func(resp *http.Response) bool {
+		head, err := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(head), resp.Body), resp.Body}
+		if err != nil {
+			return false
+		}
+		return hasMediaType(http.DetectContentType(head), types)
+	})
+}
+
+// Reports whether the Content-Type is any of the media types, see MIMEType.
+func hasMediaType(contentType string, types []string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, t := range types {
+		t = strings.ToLower(t)
+		if t == "*/*" || t == mediaType {
+			return true
+		}
+		if family, ok := strings.CutSuffix(t, "/*"); ok && strings.HasPrefix(mediaType, family+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// HasHeader matches responses that have the header, with any value.
+func HasHeader(name string) NamedMatcher {
+	return Named("HasHeader("+name+")", func(resp *http.Response) bool {
+		return len(resp.Header.Values(name)) > 0
+	})
+}
+
+// HeaderMatches matches responses with a value of the header that matches the regex.
+func HeaderMatches(name string, re *regexp.Regexp) NamedMatcher {
+	return Named(fmt.Sprintf("HeaderMatches(%s, %s)", name, re), func(resp *http.Response) bool {
+		for _, v := range resp.Header.Values(name) {
+			if re.MatchString(v) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// ContentLengthBetween matches responses with a Content-Length between lo and hi bytes,
+// inclusive, where hi <= 0 is no limit. Responses of unknown length (e.g. chunked) match, as
+// their length can only be limited with Config.MaxBodyBytes.
+func ContentLengthBetween(lo, hi int64) NamedMatcher {
+	return Named(fmt.Sprintf("ContentLengthBetween(%d, %d)", lo, hi), func(resp *http.Response) bool {
+		if resp.ContentLength < 0 {
+			return true
+		}
+		return resp.ContentLength >= lo && (hi <= 0 || resp.ContentLength <= hi)
+	})
+}
+
+// FinalURLMatches matches responses whose final URL, after redirects, matches the regex.
+func FinalURLMatches(re *regexp.Regexp) NamedMatcher {
+	return Named(fmt.Sprintf("FinalURLMatches(%s)", re), func(resp *http.Response) bool {
+		return resp.Request != nil && re.MatchString(resp.Request.URL.String())
+	})
 }
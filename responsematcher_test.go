@@ -0,0 +1,150 @@
+package gocrawler
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func newMatcherResponse(status int, contentType, body string) *http.Response {
+	resp := &http.Response{
+		StatusCode:    status,
+		Header:        make(http.Header),
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+	}
+	if contentType != "" {
+		resp.Header.Set("Content-Type", contentType)
+	}
+	return resp
+}
+
+// Returns the matcher with the name, which is reported instead of the names of its matchers.
+func rename(name string, m Matcher) NamedMatcher {
+	return Named(name, func(resp *http.Response) bool {
+		ok, _ := m.MatchResponse(resp)
+		return ok
+	})
+}
+
+func TestMatchResponse(t *testing.T) {
+	isJSON := rename("IsJSON", MIMEType("application/json"))
+	isOK := ResponseMatcher(IsOkResponse)
+	tests := []struct {
+		name       string
+		m          Matcher
+		resp       *http.Response
+		exp        bool
+		rejectedBy string
+	}{
+		{"func", isOK, newMatcherResponse(404, "", ""), false, "IsOkResponse"},
+		{"named", isJSON, newMatcherResponse(200, "text/html", ""), false, "IsJSON"},
+		{"and", And(isOK, isJSON), newMatcherResponse(200, "application/json; charset=utf-8", ""), true, ""},
+		{"and, first failed", And(isOK, isJSON), newMatcherResponse(500, "text/html", ""), false, "IsOkResponse"},
+		{"and, second failed", And(isOK, isJSON), newMatcherResponse(200, "text/html", ""), false, "IsJSON"},
+		{"and, nested", And(isOK, And(HasHeader("X-Test"), isJSON)), newMatcherResponse(200, "text/html", ""), false, "HasHeader(X-Test)"},
+		{"or", Or(isJSON, ResponseMatcher(IsHtmlContent)), newMatcherResponse(200, "text/html", ""), true, ""},
+		{"or, none matched", Or(isJSON, ResponseMatcher(IsHtmlContent)), newMatcherResponse(200, "text/plain", ""), false, "Or(IsJSON, IsHtmlContent)"},
+		{"not", Not(ResponseMatcher(IsServerErrorResponse)), newMatcherResponse(200, "", ""), true, ""},
+		{"not, matched", Not(ResponseMatcher(IsServerErrorResponse)), newMatcherResponse(503, "", ""), false, "Not(IsServerErrorResponse)"},
+		{"not, named", Not(StatusBetween(300, 399)), newMatcherResponse(301, "", ""), false, "Not(StatusBetween(300, 399))"},
+		{"named combinator", rename("IsPage", And(isOK, isJSON)), newMatcherResponse(404, "", ""), false, "IsPage"},
+	}
+	for _, tt := range tests {
+		ok, rejectedBy := tt.m.MatchResponse(tt.resp)
+		if ok != tt.exp || rejectedBy != tt.rejectedBy {
+			t.Errorf("%s: expected %v, %q, got %v, %q", tt.name, tt.exp, tt.rejectedBy, ok, rejectedBy)
+		}
+	}
+}
+
+func TestStatusMatchers(t *testing.T) {
+	tests := []struct {
+		m      Matcher
+		status int
+		exp    bool
+	}{
+		{ResponseMatcher(IsClientErrorResponse), 404, true},
+		{ResponseMatcher(IsClientErrorResponse), 500, false},
+		{ResponseMatcher(IsServerErrorResponse), 500, true},
+		{ResponseMatcher(IsServerErrorResponse), 599, true},
+		{ResponseMatcher(IsServerErrorResponse), 600, true},
+		{ResponseMatcher(IsServerErrorResponse), 499, false},
+		{StatusBetween(200, 299), 204, true},
+		{StatusBetween(200, 299), 300, false},
+	}
+	for _, tt := range tests {
+		if got, _ := tt.m.MatchResponse(newMatcherResponse(tt.status, "", "")); got != tt.exp {
+			t.Errorf("Expected %s to match %d: %v, got %v", MatcherName(tt.m), tt.status, tt.exp, got)
+		}
+	}
+}
+
+func TestMIMEType(t *testing.T) {
+	tests := []struct {
+		types       []string
+		contentType string
+		exp         bool
+	}{
+		{[]string{"text/html"}, "text/html; charset=utf-8", true},
+		{[]string{"text/html"}, "TEXT/HTML", true},
+		{[]string{"text/html"}, "application/xhtml+xml", false},
+		{[]string{"image/*"}, "image/png", true},
+		{[]string{"image/*"}, "imagex/png", false},
+		{[]string{"IMAGE/*"}, "image/svg+xml", true},
+		{[]string{"application/pdf", "image/*"}, "image/webp", true},
+		{[]string{"*/*"}, "font/woff2", true},
+		{[]string{"*/*"}, "", false},
+		{[]string{"text/html"}, "not a media type", false},
+	}
+	for _, tt := range tests {
+		m := MIMEType(tt.types...)
+		if got, _ := m.MatchResponse(newMatcherResponse(200, tt.contentType, "")); got != tt.exp {
+			t.Errorf("Expected %s to match %q: %v, got %v", m.Name, tt.contentType, tt.exp, got)
+		}
+	}
+}
+
+func TestSniffedType(t *testing.T) {
+	body := "<!DOCTYPE html><html><body>" + strings.Repeat("a", 1000) + "</body></html>"
+	tests := []struct {
+		types []string
+		exp   bool
+	}{
+		{[]string{"text/html"}, true},
+		{[]string{"text/*"}, true},
+		{[]string{"application/pdf"}, false},
+	}
+	for _, tt := range tests {
+		resp := newMatcherResponse(200, "application/octet-stream", body)
+		if got, _ := SniffedType(tt.types...).MatchResponse(resp); got != tt.exp {
+			t.Errorf("Expected %v to match the sniffed type: %v, got %v", tt.types, tt.exp, got)
+		}
+		// the bytes that were sniffed are put back
+		got, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != body {
+			t.Errorf("Expected the body to be read in full, got %d of %d bytes", len(got), len(body))
+		}
+	}
+}
+
+func TestMatcherName(t *testing.T) {
+	tests := []struct {
+		m   Matcher
+		exp string
+	}{
+		{ResponseMatcher(IsHtmlContent), "IsHtmlContent"},
+		{Named("IsPage", IsOkResponse), "IsPage"},
+		{And(ResponseMatcher(IsOkResponse), MIMEType("text/html", "application/xhtml+xml")), "And(IsOkResponse, MIMEType(text/html, application/xhtml+xml))"},
+		{Or(ResponseMatcher(IsClientErrorResponse), Not(ResponseMatcher(IsServerErrorResponse))), "Or(IsClientErrorResponse, Not(IsServerErrorResponse))"},
+	}
+	for _, tt := range tests {
+		if got := MatcherName(tt.m); got != tt.exp {
+			t.Errorf("Expected %q, got %q", tt.exp, got)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+// Package scope decides which links are crawled with declarative include and exclude rules on
+// the host, path, scheme, file extension and query of a link, so that link extractors do not
+// have to filter links themselves.
+package scope
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/yusufaine/gocrawler/hostrule"
+)
+
+// Rule matches a link if every condition that is set matches, where a condition with multiple
+// values matches if any of the values match. A rule without conditions matches every link.
+type Rule struct {
+	// Hosts are matched with the same syntax as the host blacklist and allowlist of the crawler,
+	// see hostrule.Parse.
+	Hosts hostrule.Rules
+	// PathPrefixes and PathRegexps are matched against the path of the link.
+	PathPrefixes []string
+	PathRegexps  []*regexp.Regexp
+	Schemes      []string
+	// Extensions are matched against the extension of the last path segment, e.g. ".pdf".
+	Extensions []string
+	// Query params are matched by name (sid), or by name and value (page=1).
+	Query []string
+	// MaxDepth limits the rule to links at or above the depth, any depth if <= 0.
+	MaxDepth int
+}
+
+// Scope of a crawl. A link is in scope if it matches any of the include rules, or if there are
+// none, and does not match any of the exclude rules.
+type Scope struct {
+	Include []Rule
+	Exclude []Rule
+}
+
+// Check reports whether the link, found at the depth, is in scope. If it is not, the reason is
+// the exclude rule that matched it, or that no include rule matched.
+func (s *Scope) Check(link string, depth int) (bool, string) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return false, "invalid url"
+	}
+
+	if len(s.Include) > 0 {
+		included := false
+		for _, r := range s.Include {
+			if r.Match(u, depth) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false, "not included"
+		}
+	}
+
+	for _, r := range s.Exclude {
+		if r.Match(u, depth) {
+			return false, "excluded by " + r.String()
+		}
+	}
+	return true, ""
+}
+
+// Match reports whether the link, found at the depth, matches every condition of the rule.
+func (r *Rule) Match(u *url.URL, depth int) bool {
+	if r.MaxDepth > 0 && depth > r.MaxDepth {
+		return false
+	}
+
+	if len(r.Schemes) > 0 && !anyMatch(r.Schemes, func(s string) bool { return strings.EqualFold(s, u.Scheme) }) {
+		return false
+	}
+
+	if len(r.Hosts) > 0 && !anyMatch(r.Hosts, func(h hostrule.Rule) bool { return h.Matches(u.Hostname()) }) {
+		return false
+	}
+
+	p := u.Path
+	if p == "" {
+		p = "/"
+	}
+	if len(r.PathPrefixes) > 0 && !anyMatch(r.PathPrefixes, func(prefix string) bool { return strings.HasPrefix(p, prefix) }) {
+		return false
+	}
+	if len(r.PathRegexps) > 0 && !anyMatch(r.PathRegexps, func(re *regexp.Regexp) bool { return re.MatchString(p) }) {
+		return false
+	}
+
+	ext := strings.ToLower(path.Ext(p))
+	if len(r.Extensions) > 0 && !anyMatch(r.Extensions, func(e string) bool { return normaliseExt(e) == ext }) {
+		return false
+	}
+
+	if len(r.Query) > 0 {
+		query := u.Query()
+		matched := anyMatch(r.Query, func(q string) bool {
+			name, value, hasValue := strings.Cut(q, "=")
+			if !hasValue {
+				return query.Has(name)
+			}
+			return query.Has(name) && query.Get(name) == value
+		})
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+func normaliseExt(ext string) string {
+	ext = strings.ToLower(ext)
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
+
+func anyMatch[T any](values []T, match func(T) bool) bool {
+	for _, v := range values {
+		if match(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseRule parses a rule from space-separated key=value conditions, where a value can have
+// multiple comma-separated alternatives, e.g. "host=example.com path=/blog/,/news/ depth=3".
+// The keys are host, path (prefix), path-regex, scheme, ext, query and depth. path-regex is not
+// split on commas, and can be repeated for alternatives instead.
+func ParseRule(s string) (Rule, error) {
+	var r Rule
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return r, fmt.Errorf("empty scope rule")
+	}
+
+	for _, field := range fields {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok || value == "" {
+			return r, fmt.Errorf("scope rule condition %q must be key=value", field)
+		}
+
+		values := strings.Split(value, ",")
+		switch key {
+		case "host":
+			for _, v := range values {
+				h, err := hostrule.Parse(v)
+				if err != nil {
+					return r, err
+				}
+				r.Hosts = append(r.Hosts, h)
+			}
+		case "path":
+			r.PathPrefixes = append(r.PathPrefixes, values...)
+		case "path-regex":
+			re, err := regexp.Compile(value)
+			if err != nil {
+				return r, fmt.Errorf("invalid path-regex %q: %w", value, err)
+			}
+			r.PathRegexps = append(r.PathRegexps, re)
+		case "scheme":
+			r.Schemes = append(r.Schemes, values...)
+		case "ext":
+			r.Extensions = append(r.Extensions, values...)
+		case "query":
+			r.Query = append(r.Query, values...)
+		case "depth":
+			depth, err := strconv.Atoi(value)
+			if err != nil || depth < 1 {
+				return r, fmt.Errorf("scope rule depth must be >= 1, got %q", value)
+			}
+			r.MaxDepth = depth
+		default:
+			return r, fmt.Errorf("unknown scope rule key %q, expected one of: host, path, path-regex, scheme, ext, query, depth", key)
+		}
+	}
+	return r, nil
+}
+
+// String returns the rule in the format parsed by ParseRule.
+func (r Rule) String() string {
+	var fields []string
+	add := func(key string, values []string) {
+		if len(values) > 0 {
+			fields = append(fields, key+"="+strings.Join(values, ","))
+		}
+	}
+	add("host", r.Hosts.Strings())
+	add("path", r.PathPrefixes)
+	for _, re := range r.PathRegexps {
+		fields = append(fields, "path-regex="+re.String())
+	}
+	add("scheme", r.Schemes)
+	add("ext", r.Extensions)
+	add("query", r.Query)
+	if r.MaxDepth > 0 {
+		fields = append(fields, "depth="+strconv.Itoa(r.MaxDepth))
+	}
+	if len(fields) == 0 {
+		return "*"
+	}
+	return strings.Join(fields, " ")
+}
+
+// RuleFlag returns a flag.Func that appends each value of the flag, parsed with ParseRule, to
+// the rules, e.g. flag.Func("include", usage, scope.RuleFlag(&s.Include)).
+func RuleFlag(rules *[]Rule) func(string) error {
+	return func(s string) error {
+		r, err := ParseRule(s)
+		if err != nil {
+			return err
+		}
+		*rules = append(*rules, r)
+		return nil
+	}
+}
+
+// FormatRules returns the rules in the format parsed by ParseRule, separated by semicolons.
+func FormatRules(rules []Rule) string {
+	s := make([]string, len(rules))
+	for i, r := range rules {
+		s[i] = r.String()
+	}
+	return strings.Join(s, "; ")
+}
@@ -0,0 +1,77 @@
+package scope_test
+
+import (
+	"testing"
+
+	"github.com/yusufaine/gocrawler/scope"
+)
+
+func mustParse(t *testing.T, s string) scope.Rule {
+	t.Helper()
+	r, err := scope.ParseRule(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestScope(t *testing.T) {
+	s := scope.Scope{
+		Include: []scope.Rule{
+			mustParse(t, "scheme=http,https host=.example.com"),
+			mustParse(t, "host=*.cdn.* path=/static/ depth=1"),
+			mustParse(t, `host=wiki.org path-regex=^/wiki/[A-Z]\w+$`),
+		},
+		Exclude: []scope.Rule{
+			mustParse(t, "ext=pdf,.ZIP"),
+			mustParse(t, "query=sid,sort=desc"),
+			mustParse(t, "path=/private"),
+		},
+	}
+
+	tests := []struct {
+		link  string
+		depth int
+		exp   bool
+	}{
+		{"https://example.com/", 1, true},
+		{"https://blog.example.com:8080/a", 5, true},
+		{"ftp://example.com/", 1, false},
+		{"https://notexample.com/", 1, false},
+		{"https://img.cdn.net/static/a.png", 1, true},
+		{"https://img.cdn.net/static/a.png", 2, false},
+		{"https://img.cdn.net/other", 1, false},
+		{"https://wiki.org/wiki/Go", 3, true},
+		{"https://wiki.org/wiki/go", 3, false},
+		{"https://example.com/a/report.PDF", 1, false},
+		{"https://example.com/a.zip", 1, false},
+		{"https://example.com/?sid=1", 1, false},
+		{"https://example.com/?sort=desc", 1, false},
+		{"https://example.com/?sort=asc", 1, true},
+		{"https://example.com/private/a", 1, false},
+	}
+	for _, tt := range tests {
+		if got, reason := s.Check(tt.link, tt.depth); got != tt.exp {
+			t.Errorf("Expected %s at depth %d to be in scope: %v, got %v (%s)", tt.link, tt.depth, tt.exp, got, reason)
+		}
+	}
+
+	// no include rules includes everything that is not excluded
+	s.Include = nil
+	if ok, _ := s.Check("https://anything.org/", 10); !ok {
+		t.Errorf("Expected links to be in scope without include rules")
+	}
+}
+
+func TestParseRule(t *testing.T) {
+	for _, s := range []string{"", "host", "host=a.com/b", "depth=0", "colour=red", "path-regex=("} {
+		if _, err := scope.ParseRule(s); err == nil {
+			t.Errorf("Expected %q to be invalid", s)
+		}
+	}
+
+	const rule = "host=example.com,exact:a.org,*.cdn.* path=/blog/ path-regex=^/\\d{1,4}/ ext=.html query=page=1 depth=2"
+	if got := mustParse(t, rule).String(); got != rule {
+		t.Errorf("Expected %q, got %q", rule, got)
+	}
+}
@@ -0,0 +1,458 @@
+// Package scrape extracts structured data from HTML pages with declarative rules, which map CSS
+// selectors or XPath expressions to named fields, lists and nested records. Rule sets apply to
+// the pages whose URL matches them, so that a single rule file can scrape different kinds of
+// pages of a site.
+//
+// An example rule file in YAML, JSON with the same keys is also supported:
+//
+//	rule_sets:
+//	  - name: products
+//	    urls: ['example\.com/products/']
+//	    fields:
+//	      - name: title
+//	        css: h1
+//	      - name: price
+//	        css: .price
+//	        regex: '[\d.]+'
+//	        type: float
+//	      - name: reviews
+//	        xpath: //div[@class="review"]
+//	        list: true
+//	        fields:
+//	          - name: stars
+//	            xpath: ./@data-stars
+//	            type: int
+//	          - name: tags
+//	            css: .tags
+//	            split: ","
+package scrape
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/cascadia"
+	"github.com/antchfx/xpath"
+	"golang.org/x/net/html"
+	"gopkg.in/yaml.v3"
+)
+
+// Types that the values of fields can be coerced to.
+const (
+	TypeString = "string"
+	TypeInt    = "int"
+	TypeFloat  = "float"
+	TypeBool   = "bool"
+	TypeURL    = "url"
+)
+
+// Rules are the rule sets of a rule file.
+type Rules struct {
+	RuleSets []RuleSet `json:"rule_sets" yaml:"rule_sets"`
+}
+
+// RuleSet is a record of fields scraped from the pages whose URL matches any of the regexes of
+// URLs, or from every page if there are none.
+type RuleSet struct {
+	Name   string   `json:"name" yaml:"name"`
+	URLs   []string `json:"urls,omitempty" yaml:"urls,omitempty"`
+	Fields []Field  `json:"fields" yaml:"fields"`
+
+	urls []*regexp.Regexp
+}
+
+// Field is a named value of a record. The value is selected with either a CSS selector or an
+// XPath expression, which are relative to the element of the record, or the whole page at the
+// top level. XPath expressions are evaluated with the element as the context node, so
+// descendants are selected with "./" or ".//", and expressions that return a number, string or
+// boolean, such as count(), are supported. A field without either is the element itself.
+//
+// The value of a selected element is its text with whitespace collapsed, or the value of its
+// Attr if set. Regex extracts its first capture group, or the whole match if it has none, and
+// Split splits the value into a list by the separator. Values are then coerced to the Type,
+// values that cannot be coerced are left out. If Fields are set, each selected element is a
+// nested record of the fields instead.
+//
+// Only the first selected element is used, unless List is set. Records without a value for a
+// Required field are left out, and so are rule sets at the top level.
+type Field struct {
+	Name     string  `json:"name" yaml:"name"`
+	CSS      string  `json:"css,omitempty" yaml:"css,omitempty"`
+	XPath    string  `json:"xpath,omitempty" yaml:"xpath,omitempty"`
+	Attr     string  `json:"attr,omitempty" yaml:"attr,omitempty"`
+	Regex    string  `json:"regex,omitempty" yaml:"regex,omitempty"`
+	Split    string  `json:"split,omitempty" yaml:"split,omitempty"`
+	Type     string  `json:"type,omitempty" yaml:"type,omitempty"`
+	List     bool    `json:"list,omitempty" yaml:"list,omitempty"`
+	Required bool    `json:"required,omitempty" yaml:"required,omitempty"`
+	Fields   []Field `json:"fields,omitempty" yaml:"fields,omitempty"`
+
+	css   cascadia.SelectorGroup
+	xpath *xpath.Expr
+	regex *regexp.Regexp
+}
+
+// Load reads the rule file at the path, as JSON if it has a .json extension and as YAML
+// otherwise.
+func Load(path string) (*Rules, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return ParseJSON(b)
+	}
+	return ParseYAML(b)
+}
+
+// ParseYAML parses and validates the rules, unknown keys are an error.
+func ParseYAML(b []byte) (*Rules, error) {
+	var r Rules
+	d := yaml.NewDecoder(bytes.NewReader(b))
+	d.KnownFields(true)
+	if err := d.Decode(&r); err != nil {
+		return nil, fmt.Errorf("unable to parse rules: %w", err)
+	}
+	return &r, r.compile()
+}
+
+// ParseJSON parses and validates the rules, unknown keys are an error.
+func ParseJSON(b []byte) (*Rules, error) {
+	var r Rules
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.DisallowUnknownFields()
+	if err := d.Decode(&r); err != nil {
+		return nil, fmt.Errorf("unable to parse rules: %w", err)
+	}
+	return &r, r.compile()
+}
+
+// Compiles the regexes, selectors and expressions of the rules, which reports invalid rules
+// before the crawl starts.
+func (r *Rules) compile() error {
+	if len(r.RuleSets) == 0 {
+		return fmt.Errorf("rules must have at least 1 rule set")
+	}
+	names := make(map[string]struct{}, len(r.RuleSets))
+	for i := range r.RuleSets {
+		rs := &r.RuleSets[i]
+		if rs.Name == "" {
+			return fmt.Errorf("rule set %d must have a name", i+1)
+		}
+		if _, ok := names[rs.Name]; ok {
+			return fmt.Errorf("duplicate rule set %q", rs.Name)
+		}
+		names[rs.Name] = struct{}{}
+
+		rs.urls = rs.urls[:0]
+		for _, u := range rs.URLs {
+			re, err := regexp.Compile(u)
+			if err != nil {
+				return fmt.Errorf("rule set %q: invalid url regex %q: %w", rs.Name, u, err)
+			}
+			rs.urls = append(rs.urls, re)
+		}
+		if err := compileFields(rs.Fields); err != nil {
+			return fmt.Errorf("rule set %q: %w", rs.Name, err)
+		}
+	}
+	return nil
+}
+
+func compileFields(fields []Field) error {
+	if len(fields) == 0 {
+		return fmt.Errorf("records must have at least 1 field")
+	}
+	names := make(map[string]struct{}, len(fields))
+	for i := range fields {
+		f := &fields[i]
+		if f.Name == "" {
+			return fmt.Errorf("field %d must have a name", i+1)
+		}
+		if _, ok := names[f.Name]; ok {
+			return fmt.Errorf("duplicate field %q", f.Name)
+		}
+		names[f.Name] = struct{}{}
+
+		if err := f.compile(); err != nil {
+			return fmt.Errorf("field %q: %w", f.Name, err)
+		}
+	}
+	return nil
+}
+
+func (f *Field) compile() error {
+	var err error
+	switch {
+	case f.CSS != "" && f.XPath != "":
+		return fmt.Errorf("only one of css and xpath can be set")
+	case f.CSS != "":
+		if f.css, err = cascadia.ParseGroup(f.CSS); err != nil {
+			return fmt.Errorf("invalid css %q: %w", f.CSS, err)
+		}
+	case f.XPath != "":
+		if f.xpath, err = xpath.Compile(f.XPath); err != nil {
+			return fmt.Errorf("invalid xpath %q: %w", f.XPath, err)
+		}
+	}
+	if f.Regex != "" {
+		if f.regex, err = regexp.Compile(f.Regex); err != nil {
+			return fmt.Errorf("invalid regex %q: %w", f.Regex, err)
+		}
+	}
+
+	switch f.Type {
+	case "", TypeString, TypeInt, TypeFloat, TypeBool, TypeURL:
+	default:
+		return fmt.Errorf("unknown type %q, expected one of: %s, %s, %s, %s, %s", f.Type, TypeString, TypeInt, TypeFloat, TypeBool, TypeURL)
+	}
+
+	if len(f.Fields) > 0 {
+		if f.Attr != "" || f.Regex != "" || f.Split != "" || f.Type != "" {
+			return fmt.Errorf("records cannot have attr, regex, split or type")
+		}
+		return compileFields(f.Fields)
+	}
+	return nil
+}
+
+// Matches reports whether the rule set applies to the page.
+func (rs *RuleSet) Matches(pageURL string) bool {
+	if len(rs.urls) == 0 {
+		return true
+	}
+	for _, re := range rs.urls {
+		if re.MatchString(pageURL) {
+			return true
+		}
+	}
+	return false
+}
+
+// Scrape returns the records of the rule sets that apply to the page keyed by their name, or
+// nil if none apply or none have the required fields. Records are maps of the field names to
+// their values, lists are []any and nested records are map[string]any.
+func (r *Rules) Scrape(pageURL string, body []byte) map[string]any {
+	var (
+		doc     *html.Node
+		base    *url.URL
+		records map[string]any
+	)
+	for i := range r.RuleSets {
+		rs := &r.RuleSets[i]
+		if !rs.Matches(pageURL) {
+			continue
+		}
+
+		// the page is only parsed if a rule set applies
+		if doc == nil {
+			var err error
+			if doc, err = html.Parse(bytes.NewReader(body)); err != nil {
+				return nil
+			}
+			base, _ = url.Parse(pageURL)
+		}
+		s := scraper{doc: doc, base: base}
+		if record, ok := s.record(rs.Fields, doc); ok {
+			if records == nil {
+				records = make(map[string]any)
+			}
+			records[rs.Name] = record
+		}
+	}
+	return records
+}
+
+type scraper struct {
+	doc  *html.Node
+	base *url.URL
+}
+
+// A selected element, or the value of an attribute or an XPath expression.
+type selection struct {
+	node  *html.Node
+	value string
+}
+
+func (s scraper) record(fields []Field, n *html.Node) (map[string]any, bool) {
+	record := make(map[string]any, len(fields))
+	for i := range fields {
+		f := &fields[i]
+		v, ok := s.field(f, n)
+		if !ok {
+			if f.Required {
+				return nil, false
+			}
+			continue
+		}
+		record[f.Name] = v
+	}
+	return record, true
+}
+
+// Returns the value of the field in the element, false if it has none. Lists have a value even
+// if they are empty, unless they are required.
+func (s scraper) field(f *Field, n *html.Node) (any, bool) {
+	selected := s.selectAll(f, n)
+	if !f.List && len(selected) > 1 {
+		selected = selected[:1]
+	}
+
+	values := make([]any, 0, len(selected))
+	for _, sel := range selected {
+		if len(f.Fields) > 0 {
+			if sel.node == nil {
+				continue
+			}
+			if record, ok := s.record(f.Fields, sel.node); ok {
+				values = append(values, record)
+			}
+			continue
+		}
+		if v, ok := s.value(f, sel); ok {
+			values = append(values, v)
+		}
+	}
+
+	if f.List {
+		return values, len(values) > 0 || !f.Required
+	}
+	if len(values) == 0 {
+		return nil, false
+	}
+	return values[0], true
+}
+
+func (s scraper) selectAll(f *Field, n *html.Node) []selection {
+	switch {
+	case f.css != nil:
+		nodes := cascadia.QueryAll(n, f.css)
+		selected := make([]selection, len(nodes))
+		for i, node := range nodes {
+			selected[i] = selection{node: node}
+		}
+		return selected
+	case f.xpath != nil:
+		return s.evaluate(f.xpath, n)
+	default:
+		return []selection{{node: n}}
+	}
+}
+
+func (s scraper) evaluate(expr *xpath.Expr, n *html.Node) []selection {
+	switch v := expr.Evaluate(newNavigator(s.doc, n)).(type) {
+	case *xpath.NodeIterator:
+		var selected []selection
+		for v.MoveNext() {
+			nav := v.Current().(*htmlNavigator)
+			if nav.attr != -1 || nav.curr.Type != html.ElementNode {
+				selected = append(selected, selection{value: nav.Value()})
+			} else {
+				selected = append(selected, selection{node: nav.curr})
+			}
+		}
+		return selected
+	case string:
+		return []selection{{value: v}}
+	case float64:
+		return []selection{{value: strconv.FormatFloat(v, 'f', -1, 64)}}
+	case bool:
+		return []selection{{value: strconv.FormatBool(v)}}
+	}
+	return nil
+}
+
+// Returns the value of the selection after the regex, split and type of the field, false if
+// the regex does not match or the value cannot be coerced.
+func (s scraper) value(f *Field, sel selection) (any, bool) {
+	text := sel.value
+	if sel.node != nil {
+		if f.Attr != "" {
+			var ok bool
+			if text, ok = attr(sel.node, f.Attr); !ok {
+				return nil, false
+			}
+		} else {
+			text = nodeText(sel.node)
+		}
+	}
+
+	if f.regex != nil {
+		m := f.regex.FindStringSubmatch(text)
+		if m == nil {
+			return nil, false
+		}
+		text = m[min(1, len(m)-1)]
+	}
+
+	if f.Split == "" {
+		return s.coerce(f.Type, collapseSpace(text))
+	}
+	parts := make([]any, 0)
+	for _, part := range strings.Split(text, f.Split) {
+		if part = collapseSpace(part); part == "" {
+			continue
+		}
+		if v, ok := s.coerce(f.Type, part); ok {
+			parts = append(parts, v)
+		}
+	}
+	return parts, true
+}
+
+// Coerces the text to the type. Thousands separators are ignored in numbers, and URLs are
+// resolved against the URL of the page.
+func (s scraper) coerce(typ, text string) (any, bool) {
+	switch typ {
+	case TypeInt:
+		i, err := strconv.ParseInt(stripSeparators(text), 10, 64)
+		return i, err == nil
+	case TypeFloat:
+		f, err := strconv.ParseFloat(stripSeparators(text), 64)
+		return f, err == nil
+	case TypeBool:
+		switch strings.ToLower(text) {
+		case "true", "yes", "y", "on", "1":
+			return true, true
+		case "false", "no", "n", "off", "0":
+			return false, true
+		}
+		return nil, false
+	case TypeURL:
+		u, err := url.Parse(text)
+		if err != nil || text == "" {
+			return nil, false
+		}
+		if s.base != nil {
+			u = s.base.ResolveReference(u)
+		}
+		return u.String(), true
+	default:
+		return text, true
+	}
+}
+
+func attr(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// Collapses runs of whitespace, including non-breaking spaces, into a single space.
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func stripSeparators(s string) string {
+	return strings.NewReplacer(",", "", "_", "", " ", "").Replace(s)
+}
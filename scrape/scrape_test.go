@@ -0,0 +1,119 @@
+package scrape_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/yusufaine/gocrawler/scrape"
+)
+
+const page = `<html><body>
+<h1> Widget&nbsp;Pro </h1>
+<p class="price">Now only $1,299.50!</p>
+<a class="more" href="/specs">Specs</a>
+<span id="stock">yes</span>
+<div class="review" data-stars="5"><p class="tags">fast, cheap ,</p><p class="by">Ann</p></div>
+<div class="review" data-stars="x"><p class="tags">slow</p></div>
+<div class="review" data-stars="3"><p class="tags"></p><p class="by">Bob</p></div>
+</body></html>`
+
+const rulesYAML = `
+rule_sets:
+  - name: product
+    urls: ['example\.com/products/']
+    fields:
+      - name: title
+        css: h1
+      - name: price
+        css: .price
+        regex: '\$([\d,.]+)'
+        type: float
+      - name: specs
+        css: a.more
+        attr: href
+        type: url
+      - name: in_stock
+        xpath: //*[@id="stock"]
+        type: bool
+      - name: review_count
+        xpath: count(//div[@class="review"])
+        type: int
+      - name: reviews
+        xpath: //div[@class="review"]
+        list: true
+        fields:
+          - name: stars
+            xpath: ./@data-stars
+            type: int
+          - name: by
+            css: .by
+            required: true
+          - name: tags
+            css: .tags
+            split: ","
+  - name: missing
+    fields:
+      - name: sku
+        css: .sku
+        required: true
+`
+
+func TestScrape(t *testing.T) {
+	rules, err := scrape.ParseYAML([]byte(rulesYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := rules.Scrape("https://example.com/products/widget", []byte(page))
+	expected := map[string]any{
+		"product": map[string]any{
+			"title":        "Widget Pro",
+			"price":        1299.5,
+			"specs":        "https://example.com/specs",
+			"in_stock":     true,
+			"review_count": int64(3),
+			"reviews": []any{
+				map[string]any{"stars": int64(5), "by": "Ann", "tags": []any{"fast", "cheap"}},
+				map[string]any{"stars": int64(3), "by": "Bob", "tags": []any{}},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		gotJSON, _ := json.Marshal(got)
+		t.Fatalf("Unexpected records: %s", gotJSON)
+	}
+
+	if got := rules.Scrape("https://example.com/about", []byte(page)); got != nil {
+		t.Errorf("Expected no records for unmatched pages, got %v", got)
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	rules, err := scrape.ParseJSON([]byte(`{"rule_sets": [{"name": "title", "fields": [{"name": "h1", "xpath": "//h1"}]}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := rules.Scrape("https://example.com/", []byte(page))
+	if h1 := got["title"].(map[string]any)["h1"]; h1 != "Widget Pro" {
+		t.Errorf("Expected the heading, got %v", h1)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for name, rules := range map[string]string{
+		"unknown key":    `rule_sets: [{name: a, fields: [{name: b, selector: h1}]}]`,
+		"no rule sets":   `rule_sets: []`,
+		"both selectors": `rule_sets: [{name: a, fields: [{name: b, css: h1, xpath: //h1}]}]`,
+		"invalid css":    `rule_sets: [{name: a, fields: [{name: b, css: "h1["}]}]`,
+		"invalid xpath":  `rule_sets: [{name: a, fields: [{name: b, xpath: "//h1["}]}]`,
+		"invalid url":    `rule_sets: [{name: a, urls: ["("], fields: [{name: b}]}]`,
+		"unknown type":   `rule_sets: [{name: a, fields: [{name: b, type: date}]}]`,
+		"duplicate":      `rule_sets: [{name: a, fields: [{name: b}, {name: b}]}]`,
+		"record type":    `rule_sets: [{name: a, fields: [{name: b, type: int, fields: [{name: c}]}]}]`,
+	} {
+		if _, err := scrape.ParseYAML([]byte(rules)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package scrape
+
+import (
+	"strings"
+
+	"github.com/antchfx/xpath"
+	"golang.org/x/net/html"
+)
+
+// Implements xpath.NodeNavigator over the HTML tree. attr is the index of the attribute of the
+// current element that the navigator is on, or -1 if it is on the element itself.
+type htmlNavigator struct {
+	root, curr *html.Node
+	attr       int
+}
+
+func newNavigator(root, curr *html.Node) *htmlNavigator {
+	return &htmlNavigator{root: root, curr: curr, attr: -1}
+}
+
+func (h *htmlNavigator) NodeType() xpath.NodeType {
+	switch h.curr.Type {
+	case html.CommentNode:
+		return xpath.CommentNode
+	case html.TextNode:
+		return xpath.TextNode
+	case html.ElementNode:
+		if h.attr != -1 {
+			return xpath.AttributeNode
+		}
+		return xpath.ElementNode
+	default:
+		// the document and the doctype
+		return xpath.RootNode
+	}
+}
+
+func (h *htmlNavigator) LocalName() string {
+	if h.attr != -1 {
+		return h.curr.Attr[h.attr].Key
+	}
+	return h.curr.Data
+}
+
+func (h *htmlNavigator) Prefix() string {
+	return ""
+}
+
+func (h *htmlNavigator) Value() string {
+	switch h.curr.Type {
+	case html.CommentNode, html.TextNode:
+		return h.curr.Data
+	case html.ElementNode:
+		if h.attr != -1 {
+			return h.curr.Attr[h.attr].Val
+		}
+	}
+	return nodeText(h.curr)
+}
+
+func (h *htmlNavigator) Copy() xpath.NodeNavigator {
+	n := *h
+	return &n
+}
+
+func (h *htmlNavigator) MoveToRoot() {
+	h.curr, h.attr = h.root, -1
+}
+
+func (h *htmlNavigator) MoveToParent() bool {
+	if h.attr != -1 {
+		h.attr = -1
+		return true
+	}
+	if h.curr.Parent == nil {
+		return false
+	}
+	h.curr = h.curr.Parent
+	return true
+}
+
+func (h *htmlNavigator) MoveToNextAttribute() bool {
+	if h.attr >= len(h.curr.Attr)-1 {
+		return false
+	}
+	h.attr++
+	return true
+}
+
+func (h *htmlNavigator) MoveToChild() bool {
+	if h.attr != -1 || h.curr.FirstChild == nil {
+		return false
+	}
+	h.curr = h.curr.FirstChild
+	return true
+}
+
+func (h *htmlNavigator) MoveToFirst() bool {
+	if h.attr != -1 || h.curr.PrevSibling == nil {
+		return false
+	}
+	for h.curr.PrevSibling != nil {
+		h.curr = h.curr.PrevSibling
+	}
+	return true
+}
+
+func (h *htmlNavigator) MoveToNext() bool {
+	if h.attr != -1 || h.curr.NextSibling == nil {
+		return false
+	}
+	h.curr = h.curr.NextSibling
+	return true
+}
+
+func (h *htmlNavigator) MoveToPrevious() bool {
+	if h.attr != -1 || h.curr.PrevSibling == nil {
+		return false
+	}
+	h.curr = h.curr.PrevSibling
+	return true
+}
+
+func (h *htmlNavigator) MoveTo(other xpath.NodeNavigator) bool {
+	node, ok := other.(*htmlNavigator)
+	if !ok || node.root != h.root {
+		return false
+	}
+	h.curr, h.attr = node.curr, node.attr
+	return true
+}
+
+// Returns the text of the node and its descendants as is.
+func nodeText(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return b.String()
+}
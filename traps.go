@@ -0,0 +1,139 @@
+package gocrawler
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/log"
+)
+
+// Trap is the heuristic that a link was skipped by to avoid a crawler trap, such as a calendar
+// with infinitely many pages or a relative link that nests the path every time it is followed.
+type Trap string
+
+const (
+	TrapPathDepth       Trap = "path_depth"
+	TrapRepeatedSegment Trap = "repeated_segment"
+	TrapURLLength       Trap = "url_length"
+	TrapQueryVariants   Trap = "query_variants"
+	TrapHostPageBudget  Trap = "host_page_budget"
+)
+
+// TrapConfig are the heuristics used to detect crawler traps, each heuristic is disabled if its
+// limit is <= 0. Links that trigger a heuristic are not crawled and are recorded with the
+// OutcomeTrap outcome. Seed URLs are always crawled.
+type TrapConfig struct {
+	MaxPathDepth        int // max number of segments in the path, e.g. /a/b/c has 3
+	MaxRepeatedSegments int // max number of times the same segment can appear in the path, e.g. /a/b/a/b has 2
+	MaxURLLength        int // max length of the URL
+	MaxQueryVariants    int // max number of distinct query strings crawled for the same host and path
+	MaxPagesPerHost     int // max number of distinct links followed to the same host
+}
+
+// Keeps the distinct links followed to each host and the query strings of each path, to detect
+// traps that only show up across multiple links. The same link can be found by several pages
+// before it is visited, so links are only counted once.
+type trapState struct {
+	mu            sync.Mutex
+	hostLinks     map[string]map[string]struct{}
+	queryVariants map[string]map[string]struct{}
+}
+
+func newTrapState() *trapState {
+	return &trapState{
+		hostLinks:     make(map[string]map[string]struct{}),
+		queryVariants: make(map[string]map[string]struct{}),
+	}
+}
+
+// Returns the trap that the link would fall into if it was crawled, if any. Links that are not
+// trapped are counted towards the query variants of their path and the page budget of their
+// host, so the link is expected to be crawled afterwards.
+func (c *Client) detectTrap(link string) (Trap, bool) {
+	parsedUrl, err := url.Parse(link)
+	if err != nil {
+		// invalid links are recorded when they are crawled
+		return "", false
+	}
+
+	var segments []string
+	for _, s := range strings.Split(parsedUrl.Path, "/") {
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+
+	if limit := c.traps.MaxURLLength; limit > 0 && len(link) > limit {
+		return TrapURLLength, true
+	}
+	if limit := c.traps.MaxPathDepth; limit > 0 && len(segments) > limit {
+		return TrapPathDepth, true
+	}
+	if limit := c.traps.MaxRepeatedSegments; limit > 0 {
+		counts := make(map[string]int, len(segments))
+		for _, s := range segments {
+			counts[s]++
+			if counts[s] > limit {
+				return TrapRepeatedSegment, true
+			}
+		}
+	}
+
+	c.trapState.mu.Lock()
+	defer c.trapState.mu.Unlock()
+
+	pathKey := parsedUrl.Host + parsedUrl.Path
+	variants := c.trapState.queryVariants[pathKey]
+	_, seenQuery := variants[parsedUrl.RawQuery]
+	if limit := c.traps.MaxQueryVariants; limit > 0 && parsedUrl.RawQuery != "" && !seenQuery && len(variants) >= limit {
+		return TrapQueryVariants, true
+	}
+	hostLinks := c.trapState.hostLinks[parsedUrl.Host]
+	_, seenLink := hostLinks[link]
+	if limit := c.traps.MaxPagesPerHost; limit > 0 && !seenLink && len(hostLinks) >= limit {
+		return TrapHostPageBudget, true
+	}
+
+	if parsedUrl.RawQuery != "" && !seenQuery {
+		if variants == nil {
+			variants = make(map[string]struct{})
+			c.trapState.queryVariants[pathKey] = variants
+		}
+		variants[parsedUrl.RawQuery] = struct{}{}
+	}
+	if !seenLink {
+		if hostLinks == nil {
+			hostLinks = make(map[string]struct{})
+			c.trapState.hostLinks[parsedUrl.Host] = hostLinks
+		}
+		hostLinks[link] = struct{}{}
+	}
+	return "", false
+}
+
+// Records the link as trapped and logs the trap that it triggered.
+func (c *Client) recordTrap(link, parent string, depth int, trap Trap) {
+	log.Warn("crawler trap detected, skipping", "trap", trap, "link", link)
+	c.RecordAttempt(link, AttemptInfo{
+		Depth:   depth,
+		Parent:  parent,
+		Outcome: OutcomeTrap,
+		Trap:    trap,
+	})
+}
+
+// Traps returns the links that were not crawled as they triggered one of the trap heuristics,
+// see TrapConfig.
+func (c *Client) Traps() map[string]AttemptInfo {
+	c.AttemptMutex.RLock()
+	defer c.AttemptMutex.RUnlock()
+
+	traps := make(map[string]AttemptInfo)
+	for link, ai := range c.Attempts {
+		if ai.Outcome == OutcomeTrap {
+			traps[link] = ai
+		}
+	}
+	return traps
+}
@@ -0,0 +1,70 @@
+package gocrawler
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectTrap(t *testing.T) {
+	tests := []struct {
+		config TrapConfig
+		link   string
+		exp    Trap
+	}{
+		{TrapConfig{MaxURLLength: 30}, "https://a.com/" + strings.Repeat("x", 20), TrapURLLength},
+		{TrapConfig{MaxURLLength: 30}, "https://a.com/short", ""},
+		{TrapConfig{MaxPathDepth: 3}, "https://a.com/a/b/c/d", TrapPathDepth},
+		{TrapConfig{MaxPathDepth: 3}, "https://a.com/a/b/c/", ""},
+		{TrapConfig{MaxRepeatedSegments: 2}, "https://a.com/a/b/a/b/a/b", TrapRepeatedSegment},
+		{TrapConfig{MaxRepeatedSegments: 2}, "https://a.com/a/b/a/b", ""},
+		{TrapConfig{}, "https://a.com/a/a/a/a/a/a/a/a/a/a/a/a/a/a/a/a/a/a/a/a/a/a/a/a", ""},
+	}
+	for _, tt := range tests {
+		c := &Client{traps: tt.config, trapState: newTrapState()}
+		if got, _ := c.detectTrap(tt.link); got != tt.exp {
+			t.Errorf("Expected %s to be trapped by %q, got %q", tt.link, tt.exp, got)
+		}
+	}
+}
+
+func TestDetectTrapQueryVariants(t *testing.T) {
+	c := &Client{traps: TrapConfig{MaxQueryVariants: 2}, trapState: newTrapState()}
+	links := []struct {
+		link string
+		exp  Trap
+	}{
+		{"https://a.com/cal?d=1", ""},
+		{"https://a.com/cal?d=2", ""},
+		{"https://a.com/cal?d=1", ""}, // already crawled variant
+		{"https://a.com/cal?d=3", TrapQueryVariants},
+		{"https://a.com/cal", ""},
+		{"https://a.com/other?d=3", ""},
+	}
+	for _, l := range links {
+		if got, _ := c.detectTrap(l.link); got != l.exp {
+			t.Errorf("Expected %s to be trapped by %q, got %q", l.link, l.exp, got)
+		}
+	}
+}
+
+func TestDetectTrapHostPageBudget(t *testing.T) {
+	c := &Client{traps: TrapConfig{MaxPagesPerHost: 2}, trapState: newTrapState()}
+	links := []struct {
+		link string
+		exp  Trap
+	}{
+		{"https://a.com/1", ""},
+		// found again by other pages before it is visited, which does not use up the budget
+		{"https://a.com/1", ""},
+		{"https://a.com/1", ""},
+		{"https://a.com/2", ""},
+		{"https://a.com/2", ""},
+		{"https://a.com/3", TrapHostPageBudget},
+		{"https://b.com/1", ""},
+	}
+	for _, l := range links {
+		if got, _ := c.detectTrap(l.link); got != l.exp {
+			t.Errorf("Expected %s to be trapped by %q, got %q", l.link, l.exp, got)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package warc
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	TypeWarcinfo = "warcinfo"
+	TypeRequest  = "request"
+	TypeResponse = "response"
+	TypeMetadata = "metadata"
+	TypeRevisit  = "revisit"
+
+	// profile of revisit records whose payload is identical to a previously archived response
+	revisitProfile = "http://netpreserve.org/warc/1.1/revisit/identical-payload-digest"
+)
+
+// Record is a single WARC record, Headers are written in order after the mandatory
+// WARC-Type, WARC-Record-ID, WARC-Date and Content-Length headers.
+type Record struct {
+	Type    string
+	ID      string
+	Date    time.Time
+	Headers [][2]string
+	Block   []byte
+}
+
+func newRecord(typ string, date time.Time, block []byte, headers ...[2]string) *Record {
+	return &Record{
+		Type:    typ,
+		ID:      newRecordID(),
+		Date:    date,
+		Headers: headers,
+		Block:   block,
+	}
+}
+
+// Bytes serialises the record, including the 2 CRLFs that end each record.
+func (r *Record) Bytes() []byte {
+	var b bytes.Buffer
+	b.WriteString("WARC/1.1\r\n")
+	fmt.Fprintf(&b, "WARC-Type: %s\r\n", r.Type)
+	fmt.Fprintf(&b, "WARC-Record-ID: %s\r\n", r.ID)
+	fmt.Fprintf(&b, "WARC-Date: %s\r\n", r.Date.UTC().Format(time.RFC3339))
+	for _, h := range r.Headers {
+		fmt.Fprintf(&b, "%s: %s\r\n", h[0], h[1])
+	}
+	fmt.Fprintf(&b, "Content-Length: %d\r\n", len(r.Block))
+	b.WriteString("\r\n")
+	b.Write(r.Block)
+	b.WriteString("\r\n\r\n")
+	return b.Bytes()
+}
+
+// Generates a random (version 4) UUID URN to be used as the WARC-Record-ID.
+func newRecordID() string {
+	var u [16]byte
+	_, _ = rand.Read(u[:])
+	u[6] = (u[6] & 0x0f) | 0x40
+	u[8] = (u[8] & 0x3f) | 0x80
+	return fmt.Sprintf("<urn:uuid:%x-%x-%x-%x-%x>", u[0:4], u[4:6], u[6:8], u[8:10], u[10:])
+}
+
+// Returns the digest in the "sha1:<base32>" format used by the WARC digest headers and CDX.
+func digest(b []byte) string {
+	sum := sha1.Sum(b)
+	return "sha1:" + base32.StdEncoding.EncodeToString(sum[:])
+}
+
+// Reconstructs the request line and headers as they were sent.
+func requestHead(req *http.Request) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s %s HTTP/1.1\r\n", req.Method, req.URL.RequestURI())
+	fmt.Fprintf(&b, "Host: %s\r\n", req.Host)
+	writeHeader(&b, req.Header)
+	b.WriteString("\r\n")
+	return b.Bytes()
+}
+
+// Reconstructs the status line and headers of the response. The client removes the
+// Content-Encoding and Content-Length headers if it decoded the body, so the headers always
+// describe the body that was read.
+func responseHead(resp *http.Response) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "HTTP/%d.%d %s\r\n", resp.ProtoMajor, resp.ProtoMinor, resp.Status)
+	writeHeader(&b, resp.Header)
+	b.WriteString("\r\n")
+	return b.Bytes()
+}
+
+func writeHeader(b *bytes.Buffer, h http.Header) {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		for _, v := range h[k] {
+			fmt.Fprintf(b, "%s: %s\r\n", k, v)
+		}
+	}
+}
+
+// Converts the URL to the Sort-friendly URI Reordering Transform (SURT) form used as the key of
+// CDX lines, e.g. "https://www.example.com/a?b" -> "com,example)/a?b".
+func surt(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	parts := strings.Split(host, ".")
+	slices.Reverse(parts)
+
+	key := strings.Join(parts, ",")
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		key += ":" + port
+	}
+	key += ")" + strings.ToLower(u.EscapedPath())
+	if u.RawQuery != "" {
+		key += "?" + u.RawQuery
+	}
+	return key
+}
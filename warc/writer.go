@@ -0,0 +1,249 @@
+// Package warc writes the HTTP exchanges made by the crawler to WARC 1.1 files so that crawls
+// can be replayed with standard web archive tooling.
+package warc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yusufaine/gocrawler"
+)
+
+const (
+	cdxHeader  = " CDX N b a m s k r M S V g"
+	cdxTimeFmt = "20060102150405"
+	software   = "gocrawler (https://github.com/yusufaine/gocrawler)"
+)
+
+// Response that is referred to by revisit records with the same payload
+type revisitTarget struct {
+	uri  string
+	date time.Time
+	id   string
+}
+
+// Writer writes every exchange as gzip-per-record WARC files, starting a new file once the
+// current one exceeds the max size, and a CDX index of the response and revisit records when
+// closed. Responses with the same payload as a previous response are written as revisit
+// records to save space.
+type Writer struct {
+	mu       sync.Mutex
+	dir      string
+	prefix   string
+	started  time.Time
+	maxBytes int64
+
+	seq      int
+	file     *os.File
+	fileName string
+	size     int64
+
+	cdx      []string
+	payloads map[string]revisitTarget
+}
+
+// NewWriter creates the first WARC file in the folder, files are named
+// "<prefix>-<timestamp>-<sequence>.warc.gz". If maxBytes is <= 0, all records are written to
+// a single file.
+func NewWriter(dir, prefix string, maxBytes int64) (*Writer, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	w := &Writer{
+		dir:      dir,
+		prefix:   prefix,
+		started:  time.Now().UTC(),
+		maxBytes: maxBytes,
+		payloads: make(map[string]revisitTarget),
+	}
+	if err := w.rotate(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// RecordExchange writes the response (or revisit), request and metadata records of the
+// exchange, implementing gocrawler.ExchangeRecorder.
+func (w *Writer) RecordExchange(ex *gocrawler.Exchange) error {
+	req := ex.Response.Request
+	uri := req.URL.String()
+	respHead := responseHead(ex.Response)
+	payloadDigest := digest(ex.Body)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.maxBytes > 0 && w.size >= w.maxBytes {
+		if err := w.rotate(); err != nil {
+			return err
+		}
+	}
+
+	var (
+		respRec *Record
+		mimeTyp = "warc/revisit"
+	)
+	if target, ok := w.payloads[payloadDigest]; ok && len(ex.Body) > 0 && !ex.Truncated {
+		respRec = newRecord(TypeRevisit, ex.FetchedAt, respHead,
+			[2]string{"WARC-Target-URI", uri},
+			[2]string{"WARC-Profile", revisitProfile},
+			[2]string{"WARC-Refers-To", target.id},
+			[2]string{"WARC-Refers-To-Target-URI", target.uri},
+			[2]string{"WARC-Refers-To-Date", target.date.UTC().Format(time.RFC3339)},
+			[2]string{"WARC-Payload-Digest", payloadDigest},
+			[2]string{"WARC-Block-Digest", digest(respHead)},
+			[2]string{"Content-Type", "application/http;msgtype=response"},
+		)
+	} else {
+		block := append(slices.Clip(respHead), ex.Body...)
+		headers := [][2]string{
+			{"WARC-Target-URI", uri},
+			{"WARC-Payload-Digest", payloadDigest},
+			{"WARC-Block-Digest", digest(block)},
+			{"Content-Type", "application/http;msgtype=response"},
+		}
+		if ex.Truncated {
+			headers = append(headers, [2]string{"WARC-Truncated", "length"})
+		}
+		respRec = newRecord(TypeResponse, ex.FetchedAt, block, headers...)
+		if len(ex.Body) > 0 && !ex.Truncated {
+			w.payloads[payloadDigest] = revisitTarget{uri: uri, date: ex.FetchedAt, id: respRec.ID}
+		}
+
+		mimeTyp = "-"
+		if mt, _, err := mime.ParseMediaType(ex.Response.Header.Get("Content-Type")); err == nil {
+			mimeTyp = mt
+		}
+	}
+
+	offset, length, err := w.write(respRec)
+	if err != nil {
+		return err
+	}
+	w.cdx = append(w.cdx, cdxLine(ex, respRec, mimeTyp, payloadDigest, offset, length, w.fileName))
+
+	reqHead := requestHead(req)
+	reqRec := newRecord(TypeRequest, ex.FetchedAt, reqHead,
+		[2]string{"WARC-Target-URI", uri},
+		[2]string{"WARC-Concurrent-To", respRec.ID},
+		[2]string{"WARC-Block-Digest", digest(reqHead)},
+		[2]string{"Content-Type", "application/http;msgtype=request"},
+	)
+	if _, _, err := w.write(reqRec); err != nil {
+		return err
+	}
+
+	metaRec := newRecord(TypeMetadata, ex.FetchedAt, metadataBlock(ex),
+		[2]string{"WARC-Target-URI", uri},
+		[2]string{"WARC-Concurrent-To", respRec.ID},
+		[2]string{"Content-Type", "application/warc-fields"},
+	)
+	_, _, err = w.write(metaRec)
+	return err
+}
+
+// Close closes the current WARC file and writes the sorted CDX index to
+// "<prefix>-<timestamp>.cdx".
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+
+	slices.Sort(w.cdx)
+	cdx := cdxHeader + "\n" + strings.Join(w.cdx, "\n") + "\n"
+	cdxPath := filepath.Join(w.dir, fmt.Sprintf("%s-%s.cdx", w.prefix, w.started.Format(cdxTimeFmt)))
+	return os.WriteFile(cdxPath, []byte(cdx), 0644)
+}
+
+// Closes the current file, if any, and starts a new file with a warcinfo record.
+func (w *Writer) rotate() error {
+	if w.file != nil {
+		if err := w.file.Close(); err != nil {
+			return err
+		}
+	}
+
+	w.fileName = fmt.Sprintf("%s-%s-%05d.warc.gz", w.prefix, w.started.Format(cdxTimeFmt), w.seq)
+	f, err := os.Create(filepath.Join(w.dir, w.fileName))
+	if err != nil {
+		return err
+	}
+	w.file = f
+	w.size = 0
+	w.seq++
+
+	info := "software: " + software + "\r\n" +
+		"format: WARC File Format 1.1\r\n" +
+		"conformsTo: http://iipc.github.io/warc-specifications/specifications/warc-format/warc-1.1/\r\n"
+	_, _, err = w.write(newRecord(TypeWarcinfo, time.Now(), []byte(info),
+		[2]string{"WARC-Filename", w.fileName},
+		[2]string{"Content-Type", "application/warc-fields"},
+	))
+	return err
+}
+
+// Writes the record as its own gzip member, returning the offset and compressed length.
+func (w *Writer) write(rec *Record) (int64, int64, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(rec.Bytes()); err != nil {
+		return 0, 0, err
+	}
+	if err := zw.Close(); err != nil {
+		return 0, 0, err
+	}
+
+	offset := w.size
+	n, err := w.file.Write(buf.Bytes())
+	w.size += int64(n)
+	return offset, int64(n), err
+}
+
+// Fields of the metadata record, following the names used by Heritrix where possible.
+func metadataBlock(ex *gocrawler.Exchange) []byte {
+	var b strings.Builder
+	if ex.Parent != "" {
+		fmt.Fprintf(&b, "via: %s\r\n", ex.Parent)
+	}
+	fmt.Fprintf(&b, "depth: %d\r\n", ex.Depth)
+	for _, link := range ex.Links {
+		fmt.Fprintf(&b, "outlink: %s\r\n", link)
+	}
+	return []byte(b.String())
+}
+
+// Formats the record as a CDX line with the fields in cdxHeader.
+func cdxLine(ex *gocrawler.Exchange, rec *Record, mimeTyp, payloadDigest string, offset, length int64, fileName string) string {
+	uri := ex.Response.Request.URL.String()
+	redirect := "-"
+	if loc, err := ex.Response.Location(); err == nil {
+		redirect = loc.String()
+	}
+
+	return strings.Join([]string{
+		surt(uri),
+		rec.Date.UTC().Format(cdxTimeFmt),
+		uri,
+		mimeTyp,
+		strconv.Itoa(ex.Response.StatusCode),
+		strings.TrimPrefix(payloadDigest, "sha1:"),
+		redirect,
+		"-",
+		strconv.FormatInt(length, 10),
+		strconv.FormatInt(offset, 10),
+		fileName,
+	}, " ")
+}
@@ -0,0 +1,107 @@
+package warc_test
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yusufaine/gocrawler"
+	"github.com/yusufaine/gocrawler/warc"
+)
+
+func newExchange(t *testing.T, link, body string) *gocrawler.Exchange {
+	t.Helper()
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &gocrawler.Exchange{
+		Response: &http.Response{
+			Status:     "200 OK",
+			StatusCode: 200,
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     http.Header{"Content-Type": {"text/html; charset=utf-8"}},
+			Request:    &http.Request{Method: "GET", URL: u, Host: u.Host, Header: http.Header{}},
+		},
+		Body:      []byte(body),
+		FetchedAt: time.Date(2023, 10, 24, 15, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestWriterRevisitAndCDX(t *testing.T) {
+	dir := t.TempDir()
+	w, err := warc.NewWriter(dir, "test", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, link := range []string{"https://www.example.com/a", "https://www.example.com/b?q=1"} {
+		if err := w.RecordExchange(newExchange(t, link, "<p>same</p>")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	cdxFiles, _ := filepath.Glob(filepath.Join(dir, "*.cdx"))
+	if len(cdxFiles) != 1 {
+		t.Fatalf("Expected 1 CDX file, got %d", len(cdxFiles))
+	}
+	cdx, err := os.ReadFile(cdxFiles[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(cdx)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected header and 2 CDX lines, got %d", len(lines))
+	}
+
+	exp := []struct {
+		key      string
+		mime     string
+		warcType string
+	}{
+		{"com,example)/a", "text/html", "WARC-Type: response"},
+		{"com,example)/b?q=1", "warc/revisit", "WARC-Type: revisit"},
+	}
+	for i, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if fields[0] != exp[i].key {
+			t.Errorf("Expected key %s, got %s", exp[i].key, fields[0])
+		}
+		if fields[3] != exp[i].mime {
+			t.Errorf("Expected mime %s, got %s", exp[i].mime, fields[3])
+		}
+
+		// the offset should point to the start of the record's own gzip member
+		offset, _ := strconv.ParseInt(fields[9], 10, 64)
+		f, err := os.Open(filepath.Join(dir, fields[10]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			t.Fatal(err)
+		}
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		zr.Multistream(false)
+		r := bufio.NewReader(zr)
+		_, _ = r.ReadString('\n')
+		typ, _ := r.ReadString('\n')
+		if strings.TrimSpace(typ) != exp[i].warcType {
+			t.Errorf("Expected %s, got %s", exp[i].warcType, typ)
+		}
+	}
+}